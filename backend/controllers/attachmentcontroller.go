@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"dompet/backend/models"
+	"dompet/backend/storage"
+	"dompet/backend/utils"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Registrasi decoder GIF untuk image.Decode
+	"image/jpeg"
+	_ "image/png" // Registrasi decoder PNG untuk image.Decode
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAttachmentSize = 10 << 20 // 10 MB
+	thumbnailSize     = 320
+	// maxImagePixels membatasi ukuran gambar yang di-decode agar file kecil
+	// berdimensi raksasa tidak menghabiskan memori
+	maxImagePixels = 40_000_000
+)
+
+// attachmentTypes adalah tipe file lampiran yang diterima beserta ekstensinya
+var attachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// getBlobStore mengembalikan blob store yang dipasang di main
+func getBlobStore(c *gin.Context) storage.BlobStore {
+	return c.MustGet("blobs").(storage.BlobStore)
+}
+
+// randomName menghasilkan nama file acak untuk key blob
+func randomName() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// readUpload membaca file multipart "file" dengan batas ukuran maxSize dan
+// mendeteksi tipe aslinya dari isi file (bukan dari nama atau header klien)
+func readUpload(c *gin.Context, maxSize int64) ([]byte, string, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+(1<<20))
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File is larger than %d MB", maxSize>>20)})
+			return nil, "", "", false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file must be uploaded in the 'file' field"})
+		return nil, "", "", false
+	}
+	if header.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File is larger than %d MB", maxSize>>20)})
+		return nil, "", "", false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return nil, "", "", false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil || int64(len(data)) > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File is larger than %d MB", maxSize>>20)})
+		return nil, "", "", false
+	}
+
+	contentType := strings.Split(mimetype.Detect(data).String(), ";")[0]
+	return data, contentType, filepath.Base(header.Filename), true
+}
+
+// decodeImage men-decode gambar setelah memeriksa dimensinya
+func decodeImage(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, errors.New("image dimensions are too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// encodeJPEG menyimpan gambar sebagai JPEG. Encoding ulang sekaligus membuang
+// metadata (EXIF) dari file asli.
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	return buf.Bytes(), err
+}
+
+// deleteBlobs menghapus blob tanpa menggagalkan request; blob yang gagal
+// dihapus hanya dicatat di log
+func deleteBlobs(c *gin.Context, keys ...string) {
+	blobs := getBlobStore(c)
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := blobs.Delete(c.Request.Context(), key); err != nil {
+			log.Println("Failed to delete blob", key+":", err)
+		}
+	}
+}
+
+// attachmentKeys mengembalikan semua key blob milik lampiran
+func attachmentKeys(attachments []models.Attachment) []string {
+	keys := make([]string, 0, len(attachments)*2)
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey, attachment.ThumbnailKey)
+	}
+	return keys
+}
+
+// UploadAttachment: Melampirkan foto struk atau PDF ke transaksi. Gambar
+// JPEG, PNG, dan GIF dibuatkan thumbnail.
+func UploadAttachment(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+	blobs := getBlobStore(c)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	data, contentType, fileName, ok := readUpload(c, maxAttachmentSize)
+	if !ok {
+		return
+	}
+	ext, allowed := attachmentTypes[contentType]
+	if !allowed {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only JPEG, PNG, GIF, WebP images and PDF files can be attached"})
+		return
+	}
+
+	prefix := fmt.Sprintf("attachments/%d/%d/%s", transaction.HouseholdID, transaction.ID, randomName())
+	attachment := models.Attachment{
+		TransactionID: transaction.ID,
+		UserID:        currentUser.ID,
+		FileName:      fileName,
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		StorageKey:    prefix + ext,
+	}
+
+	var thumbnail []byte
+	if contentType == "image/jpeg" || contentType == "image/png" || contentType == "image/gif" {
+		img, err := decodeImage(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Image could not be read: " + err.Error()})
+			return
+		}
+		thumbnail, err = encodeJPEG(utils.ResizeToFit(img, thumbnailSize, thumbnailSize))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create thumbnail"})
+			return
+		}
+		attachment.ThumbnailKey = prefix + "_thumb.jpg"
+	}
+
+	if err := blobs.Put(c.Request.Context(), attachment.StorageKey, data, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+	if thumbnail != nil {
+		if err := blobs.Put(c.Request.Context(), attachment.ThumbnailKey, thumbnail, "image/jpeg"); err != nil {
+			deleteBlobs(c, attachment.StorageKey)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store thumbnail"})
+			return
+		}
+	}
+
+	if err := db.Create(&attachment).Error; err != nil {
+		deleteBlobs(c, attachment.StorageKey, attachment.ThumbnailKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attachment"})
+		return
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+
+	c.JSON(http.StatusOK, gin.H{"data": attachment})
+}
+
+// GetTransactionAttachments: Mendapatkan daftar lampiran transaksi
+func GetTransactionAttachments(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var attachments []models.Attachment
+	db.Where("transaction_id = ?", transaction.ID).Order("created_at").Find(&attachments)
+	c.JSON(http.StatusOK, gin.H{"data": attachments})
+}
+
+// loadAttachmentWithRole mencari lampiran dan memastikan user punya minimal
+// peran minRole pada dompet transaksinya
+func loadAttachmentWithRole(c *gin.Context, db *gorm.DB, minRole string) (models.Attachment, bool) {
+	var attachment models.Attachment
+	currentUser, _ := getCurrentUser(c)
+
+	if err := db.First(&attachment, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return attachment, false
+	}
+	_, err := findTransactionWithRole(db, attachment.TransactionID, currentUser.ID, minRole)
+	switch {
+	case err == nil:
+		return attachment, true
+	case errors.Is(err, errWalletForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to modify this attachment"})
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+	}
+	return attachment, false
+}
+
+// DownloadAttachment: Mengunduh isi lampiran, atau thumbnail-nya dengan
+// ?thumbnail=true
+func DownloadAttachment(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	attachment, ok := loadAttachmentWithRole(c, db, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	key, contentType, size := attachment.StorageKey, attachment.ContentType, attachment.Size
+	if c.Query("thumbnail") == "true" {
+		if attachment.ThumbnailKey == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This attachment has no thumbnail"})
+			return
+		}
+		key, contentType, size = attachment.ThumbnailKey, "image/jpeg", -1
+	}
+
+	reader, err := getBlobStore(c).Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment file is missing"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read attachment"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, size, contentType, reader, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("inline; filename=%q", attachment.FileName),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment: Menghapus lampiran beserta file-nya
+func DeleteAttachment(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	attachment, ok := loadAttachmentWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	if err := db.Delete(&attachment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+	deleteBlobs(c, attachment.StorageKey, attachment.ThumbnailKey)
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Attachment deleted successfully"})
+}
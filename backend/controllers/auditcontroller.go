@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// auditEntities adalah nilai ?entity= yang diterima GetAuditLog
+var auditEntities = map[string]bool{
+	models.AuditEntityWallet:         true,
+	models.AuditEntityCategory:       true,
+	models.AuditEntityTransaction:    true,
+	models.AuditEntityProfile:        true,
+	models.AuditEntityRule:           true,
+	models.AuditEntityReconciliation: true,
+}
+
+// findAuditEntity memastikan user boleh melihat riwayat entitas. Entitas yang
+// sudah dihapus tidak bisa dicek perannya, sehingga riwayatnya hanya
+// ditampilkan bila tercatat di household aktif (householdOnly bernilai true).
+func findAuditEntity(c *gin.Context, db *gorm.DB, entity, id string) (householdOnly bool, err error) {
+	currentUser, _ := getCurrentUser(c)
+	switch entity {
+	case models.AuditEntityWallet:
+		_, err = findWalletWithRole(db, id, currentUser.ID, models.RoleViewer)
+	case models.AuditEntityTransaction:
+		_, err = findTransactionWithRole(db, id, currentUser.ID, models.RoleViewer)
+	case models.AuditEntityCategory:
+		var category models.Category
+		err = db.Scopes(tenantScope(c)).First(&category, "id = ?", id).Error
+	case models.AuditEntityRule:
+		var rule models.TransactionRule
+		err = db.Scopes(tenantScope(c)).First(&rule, "id = ?", id).Error
+	case models.AuditEntityReconciliation:
+		var reconciliation models.Reconciliation
+		if err = db.First(&reconciliation, "id = ?", id).Error; err == nil {
+			_, err = findWalletWithRole(db, reconciliation.WalletID, currentUser.ID, models.RoleViewer)
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+// GetAuditLog: Riwayat perubahan data keuangan. Dengan ?entity= dan ?id=
+// menampilkan riwayat satu dompet/kategori/transaksi/aturan/rekonsiliasi (atau ?entity=profile
+// untuk profil sendiri); tanpa id menampilkan perubahan terbaru di household
+// aktif. Jumlah baris dibatasi ?limit= (default 100).
+func GetAuditLog(c *gin.Context) {
+	var logs []models.AuditLog
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	entity := c.Query("entity")
+	id := c.Query("id")
+	if entity != "" && !auditEntities[entity] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity must be one of wallet, category, transaction, profile, rule, reconciliation"})
+		return
+	}
+	if id != "" && entity == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity is required when id is given"})
+		return
+	}
+	if id != "" {
+		if _, err := strconv.ParseUint(id, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a number"})
+			return
+		}
+	}
+
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = parsed
+	}
+
+	query := db.Preload("User").Order("created_at DESC, id DESC").Limit(limit)
+	switch {
+	case entity == models.AuditEntityProfile:
+		// Riwayat profil hanya bisa dilihat pemiliknya
+		if id != "" && id != strconv.FormatUint(uint64(currentUser.ID), 10) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view the history of your own profile"})
+			return
+		}
+		query = query.Where("entity = ? AND entity_id = ?", entity, currentUser.ID)
+	case id != "":
+		householdOnly, err := findAuditEntity(c, db, entity, id)
+		if errors.Is(err, errWalletForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log"})
+			return
+		}
+		query = query.Where("entity = ? AND entity_id = ?", entity, id)
+		if householdOnly {
+			query = query.Where("household_id = ?", getCurrentHousehold(c).ID)
+		}
+	default:
+		query = query.Where("household_id = ?", getCurrentHousehold(c).ID)
+		if entity != "" {
+			query = query.Where("entity = ?", entity)
+		}
+	}
+
+	if err := query.Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": logs})
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"dompet/backend/database"
 	"dompet/backend/models"
 	"dompet/backend/utils"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -14,6 +16,7 @@ type RegisterInput struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
+	Language string `json:"language" binding:"omitempty,max=10"`
 }
 
 func Register(c *gin.Context) {
@@ -24,6 +27,11 @@ func Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Bahasa yang dikenal mengikuti daftar kategori bawaan yang dipakai
+	if input.Language != "" && !utils.IsSupportedLanguage(input.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "language must be one of: " + strings.Join(utils.SupportedLanguages(), ", ")})
+		return
+	}
 
 	var existingUser models.User
 	if err := db.Where("email = ?", input.Email).First(&existingUser).Error; err == nil {
@@ -40,13 +48,30 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	language := input.Language
+	if language == "" {
+		language = utils.DefaultLanguage
+	}
 	user := models.User{
 		Name:         input.Name,
 		Email:        input.Email,
 		PasswordHash: string(hashedPassword),
+		Language:     language,
 	}
 
-	if err := db.Create(&user).Error; err != nil {
+	// User baru langsung mendapat household pribadi berisi kategori bawaan
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		household, err := database.EnsurePersonalHousehold(tx, user)
+		if err != nil {
+			return err
+		}
+		_, err = database.SeedDefaultCategories(tx, household.ID, user.ID, user.Language)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat akun."})
 		return
 	}
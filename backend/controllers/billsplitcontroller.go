@@ -0,0 +1,750 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"dompet/backend/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BillParticipantInput struct {
+	Name   string  `json:"name"` // Kosong untuk user sendiri
+	Shares float64 `json:"shares" binding:"omitempty,gt=0"`
+	Amount float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+type BillSplitInput struct {
+	Description string    `json:"description" binding:"required"`
+	TotalAmount float64   `json:"total_amount" binding:"required,gt=0"`
+	Method      string    `json:"method" binding:"required,oneof=equal shares exact"`
+	BillDate    time.Time `json:"bill_date" binding:"required"`
+	Currency    string    `json:"currency" binding:"omitempty,max=5"`
+	// Nama peserta yang membayar tagihan; kosong berarti user sendiri
+	PaidBy string `json:"paid_by"`
+	// Dompet pembayar, wajib bila user sendiri yang membayar
+	WalletID *uint `json:"wallet_id"`
+	// Kategori pengeluaran untuk bagian user sendiri, wajib bila user ikut
+	// menanggung tagihan
+	CategoryID   *uint                  `json:"category_id"`
+	Participants []BillParticipantInput `json:"participants" binding:"required,min=1,dive"`
+}
+
+type BillSettlementInput struct {
+	From        string    `json:"from"` // Kosong berarti user sendiri
+	To          string    `json:"to"`   // Kosong berarti user sendiri
+	Amount      float64   `json:"amount" binding:"required,gt=0"`
+	Currency    string    `json:"currency" binding:"omitempty,max=5"`
+	SettledDate time.Time `json:"settled_date" binding:"required"`
+	// Dompet tempat uang keluar/masuk, wajib bila user sendiri yang membayar
+	// atau menerima
+	WalletID *uint `json:"wallet_id"`
+}
+
+// BillBalance adalah saldo bersih satu orang pada tagihan bersama. Positif
+// berarti ia masih harus menerima, negatif berarti masih harus membayar.
+// Anggota household ditandai UserID; nama kosong berarti user sendiri.
+type BillBalance struct {
+	Name    string  `json:"name"`
+	UserID  *uint   `json:"user_id,omitempty"`
+	Balance float64 `json:"balance"`
+}
+
+// BillSettlePayment adalah satu pembayaran yang disarankan untuk melunasi
+// saldo tagihan bersama
+type BillSettlePayment struct {
+	From       string  `json:"from"`
+	FromUserID *uint   `json:"from_user_id,omitempty"`
+	To         string  `json:"to"`
+	ToUserID   *uint   `json:"to_user_id,omitempty"`
+	Amount     float64 `json:"amount"`
+}
+
+// billParty adalah satu pihak pada saldo tagihan bersama: anggota household
+// (userID) atau orang luar yang dicatat dengan nama
+type billParty struct {
+	userID uint
+	name   string
+}
+
+// BillBalanceSummary adalah saldo dan saran pelunasan untuk satu mata uang
+type BillBalanceSummary struct {
+	Currency string              `json:"currency"`
+	Balances []BillBalance       `json:"balances"`
+	SettleUp []BillSettlePayment `json:"settle_up"`
+}
+
+// errInvalidBill dikembalikan bila tagihan bersama atau pelunasannya tidak valid
+var errInvalidBill = errors.New("invalid bill split")
+
+// sharedBillsAccount adalah akun aset penampung talangan tagihan bersama:
+// saldonya sama dengan jumlah yang masih harus diterima user dari peserta lain
+func sharedBillsAccount(tx *gorm.DB, householdID, userID uint) (models.Account, error) {
+	return systemAccount(tx, householdID, userID, "shared_bills", "Piutang Patungan", models.AccountAsset)
+}
+
+// buildBillParticipants memvalidasi peserta dan menghitung bagian masing-masing
+// sesuai cara pembagian
+func buildBillParticipants(input BillSplitInput) ([]models.BillParticipant, error) {
+	participants := make([]models.BillParticipant, 0, len(input.Participants))
+	seen := map[string]bool{}
+	weights := make([]float64, 0, len(input.Participants))
+	var exactTotal int64
+	for _, p := range input.Participants {
+		name := strings.TrimSpace(p.Name)
+		if len(name) > 100 {
+			return nil, fmt.Errorf("%w: participant names may be at most 100 characters", errInvalidBill)
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: participant %q is listed more than once", errInvalidBill, name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		participant := models.BillParticipant{Name: name}
+		switch input.Method {
+		case models.SplitEqual:
+			weights = append(weights, 1)
+		case models.SplitShares:
+			if p.Shares <= 0 {
+				return nil, fmt.Errorf("%w: every participant needs shares greater than 0", errInvalidBill)
+			}
+			participant.Shares = p.Shares
+			weights = append(weights, p.Shares)
+		case models.SplitExact:
+			if p.Amount <= 0 {
+				return nil, fmt.Errorf("%w: every participant needs an amount greater than 0", errInvalidBill)
+			}
+			participant.Amount = p.Amount
+			exactTotal += toCents(p.Amount)
+		}
+		participants = append(participants, participant)
+	}
+
+	if input.Method == models.SplitExact {
+		if exactTotal != toCents(input.TotalAmount) {
+			return nil, fmt.Errorf("%w: participant amounts must add up to total_amount", errInvalidBill)
+		}
+		return participants, nil
+	}
+	for i, cents := range utils.SplitByWeight(toCents(input.TotalAmount), weights) {
+		participants[i].Amount = float64(cents) / 100
+	}
+	return participants, nil
+}
+
+// billNames memetakan nama (huruf kecil) ke ejaan yang pertama kali dipakai
+// di tagihan bersama dan pelunasan household, agar "Budi" dan "budi" dihitung
+// sebagai orang yang sama
+func billNames(tx *gorm.DB, householdID uint) (map[string]string, error) {
+	var names []string
+	err := tx.Raw(`SELECT name FROM (
+			SELECT bill_participants.name, bill_splits.id AS bill_id, bill_participants.id AS row_id FROM bill_participants
+				JOIN bill_splits ON bill_splits.id = bill_participants.bill_split_id WHERE bill_splits.household_id = ?
+			UNION ALL
+			SELECT paid_by, id, 0 FROM bill_splits WHERE household_id = ?
+		) names ORDER BY bill_id, row_id`, householdID, householdID).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+	var settlements []models.BillSettlement
+	if err := tx.Where("household_id = ?", householdID).Order("id").Find(&settlements).Error; err != nil {
+		return nil, err
+	}
+	for _, settlement := range settlements {
+		names = append(names, settlement.FromName, settlement.ToName)
+	}
+
+	known := map[string]string{}
+	for _, name := range names {
+		canonicalBillName(known, name)
+	}
+	return known, nil
+}
+
+// canonicalBillName mengembalikan ejaan nama yang sudah dikenal, atau
+// mendaftarkan name sebagai ejaan baru
+func canonicalBillName(known map[string]string, name string) string {
+	if name == "" {
+		return name
+	}
+	key := strings.ToLower(name)
+	if spelling, ok := known[key]; ok {
+		return spelling
+	}
+	known[key] = name
+	return name
+}
+
+// billWallet memuat dompet yang dipakai user untuk tagihan bersama atau
+// pelunasannya. Dompet harus ada di household aktif dan user minimal editor.
+func billWallet(tx *gorm.DB, walletID *uint, householdID, userID uint) (models.Wallet, error) {
+	if walletID == nil {
+		return models.Wallet{}, fmt.Errorf("%w: wallet_id is required when you pay or receive money", errInvalidBill)
+	}
+	wallet, err := findWalletWithRole(tx, *walletID, userID, models.RoleEditor)
+	if errors.Is(err, errWalletForbidden) {
+		return wallet, err
+	}
+	if err != nil || wallet.HouseholdID != householdID {
+		return wallet, fmt.Errorf("%w: wallet not found in this household", errInvalidBill)
+	}
+	return wallet, ensureWalletActive(wallet)
+}
+
+// billCurrency menentukan mata uang tagihan: mengikuti dompet bila ada, lalu
+// input, lalu mata uang default user
+func billCurrency(input string, wallet *models.Wallet, user models.User) (string, error) {
+	currency := strings.ToUpper(input)
+	if wallet != nil {
+		if currency != "" && currency != wallet.Currency {
+			return "", fmt.Errorf("%w: currency must match the currency of the wallet", errInvalidBill)
+		}
+		return wallet.Currency, nil
+	}
+	if currency == "" {
+		currency = user.Currency
+	}
+	return currency, nil
+}
+
+// postBillSplit membukukan tagihan bersama. Bila user yang membayar, bagiannya
+// dicatat sebagai transaksi pengeluaran biasa dan bagian peserta lain sebagai
+// talangan dari dompet ke akun Piutang Patungan. Bila orang lain yang
+// membayar, bagian user dicatat sebagai beban yang ditalangi (mengurangi
+// Piutang Patungan) tanpa menyentuh dompet.
+func postBillSplit(tx *gorm.DB, bill *models.BillSplit, wallet *models.Wallet, categoryID *uint) error {
+	var selfShare float64
+	for _, participant := range bill.Participants {
+		if participant.Name == "" {
+			selfShare = participant.Amount
+		}
+	}
+
+	var category models.Category
+	if toCents(selfShare) > 0 {
+		if categoryID == nil {
+			return fmt.Errorf("%w: category_id is required for your share of the bill", errInvalidBill)
+		}
+		if err := tx.Scopes(householdScope(bill.HouseholdID)).First(&category, *categoryID).Error; err != nil {
+			return fmt.Errorf("%w: category not found in this household", errInvalidBill)
+		}
+		if category.Type != "expense" {
+			return fmt.Errorf("%w: category_id must be an expense category", errInvalidBill)
+		}
+		if err := ensureCategoryActive(category); err != nil {
+			return err
+		}
+	}
+
+	shared, err := sharedBillsAccount(tx, bill.HouseholdID, bill.UserID)
+	if err != nil {
+		return err
+	}
+
+	entry := models.JournalEntry{
+		UserID:      bill.UserID,
+		HouseholdID: bill.HouseholdID,
+		Kind:        models.JournalAdjustment,
+		EntryDate:   bill.BillDate,
+	}
+	if bill.PaidBy != "" {
+		if toCents(selfShare) == 0 {
+			return nil
+		}
+		expense, err := categoryAccount(tx, category)
+		if err != nil {
+			return err
+		}
+		entry.Description = "Patungan " + bill.Description + " (dibayar " + bill.PaidBy + ")"
+		entry.Postings = movePostings(expense.ID, shared.ID, selfShare)
+		if err := postJournal(tx, &entry); err != nil {
+			return err
+		}
+		bill.JournalEntryID = &entry.ID
+		return nil
+	}
+
+	if toCents(selfShare) > 0 {
+		transaction := models.Transaction{
+			UserID:          bill.UserID,
+			HouseholdID:     bill.HouseholdID,
+			WalletID:        wallet.ID,
+			CategoryID:      category.ID,
+			Amount:          selfShare,
+			Type:            category.Type,
+			Description:     bill.Description,
+			TransactionDate: bill.BillDate,
+			Status:          models.TransactionCleared,
+		}
+		if err := learnTransaction(tx, transaction, 1); err != nil {
+			return err
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+		if err := postTransaction(tx, transaction); err != nil {
+			return err
+		}
+		bill.TransactionID = &transaction.ID
+	}
+
+	advance := float64(toCents(bill.TotalAmount)-toCents(selfShare)) / 100
+	if advance <= 0 {
+		return nil
+	}
+	account, err := walletAccount(tx, *wallet)
+	if err != nil {
+		return err
+	}
+	entry.Description = "Talangan " + bill.Description
+	entry.Postings = movePostings(shared.ID, account.ID, advance)
+	if err := postJournal(tx, &entry); err != nil {
+		return err
+	}
+	bill.JournalEntryID = &entry.ID
+	return nil
+}
+
+// deleteBillJournal membatalkan jurnal tagihan bersama atau pelunasan. Jurnal
+// yang sudah dibatalkan bersama dompetnya cukup dihapus.
+func deleteBillJournal(tx *gorm.DB, entryID *uint) error {
+	if entryID == nil {
+		return nil
+	}
+	var entry models.JournalEntry
+	err := tx.First(&entry, *entryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Unscoped().Where("id = ?", *entryID).Delete(&models.JournalEntry{}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return deleteJournal(tx, entry)
+}
+
+// writeBillError menulis respons untuk kegagalan mencatat tagihan bersama
+func writeBillError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errInvalidBill):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errWalletForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, errTransactionReconciled) || errors.Is(err, errExpenseHasRefunds):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case isWalletRuleError(err):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bill split failed: " + err.Error()})
+	}
+}
+
+// GetAllBillSplits: Mendapatkan semua tagihan bersama household aktif
+func GetAllBillSplits(c *gin.Context) {
+	var bills []models.BillSplit
+	db := c.MustGet("db").(*gorm.DB)
+
+	db.Scopes(tenantScope(c)).Preload("Participants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Order("bill_date desc, id desc").Find(&bills)
+
+	c.JSON(http.StatusOK, gin.H{"data": bills})
+}
+
+// CreateBillSplit: Mencatat tagihan bersama, siapa yang membayar dan bagian
+// tiap peserta (dibagi rata, sesuai porsi, atau nominal pasti)
+func CreateBillSplit(c *gin.Context) {
+	var input BillSplitInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	participants, err := buildBillParticipants(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	known, err := billNames(db, getCurrentHousehold(c).ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bill splits"})
+		return
+	}
+	for i := range participants {
+		participants[i].Name = canonicalBillName(known, participants[i].Name)
+		if participants[i].Name == "" {
+			participants[i].UserID = &currentUser.ID
+		}
+	}
+	paidBy := strings.TrimSpace(input.PaidBy)
+	others := 0
+	for _, participant := range participants {
+		if strings.EqualFold(participant.Name, paidBy) {
+			// Pakai ejaan nama peserta agar saldonya terhitung ke orang yang sama
+			paidBy = participant.Name
+		} else {
+			others++
+		}
+	}
+	// Pembayar yang tidak ikut menanggung tetap memakai ejaan yang sudah dikenal
+	paidBy = canonicalBillName(known, paidBy)
+	if others == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A bill split needs at least one participant besides the payer"})
+		return
+	}
+
+	bill := models.BillSplit{
+		UserID:       currentUser.ID,
+		HouseholdID:  getCurrentHousehold(c).ID,
+		Description:  input.Description,
+		TotalAmount:  input.TotalAmount,
+		Method:       input.Method,
+		BillDate:     input.BillDate,
+		PaidBy:       paidBy,
+		Participants: participants,
+	}
+	if bill.PaidBy == "" {
+		bill.PaidByUserID = &currentUser.ID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var wallet *models.Wallet
+		if bill.PaidBy == "" {
+			loaded, err := billWallet(tx, input.WalletID, bill.HouseholdID, currentUser.ID)
+			if err != nil {
+				return err
+			}
+			wallet = &loaded
+			bill.WalletID = &loaded.ID
+		}
+		currency, err := billCurrency(input.Currency, wallet, currentUser)
+		if err != nil {
+			return err
+		}
+		bill.Currency = currency
+
+		if err := postBillSplit(tx, &bill, wallet, input.CategoryID); err != nil {
+			return err
+		}
+		return tx.Create(&bill).Error
+	})
+	if err != nil {
+		writeBillError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": bill, "message": "Bill split created successfully"})
+}
+
+// GetBillSplitByID: Mendapatkan satu tagihan bersama beserta pesertanya
+func GetBillSplitByID(c *gin.Context) {
+	var bill models.BillSplit
+	db := c.MustGet("db").(*gorm.DB)
+
+	if err := db.Scopes(tenantScope(c)).Preload("Participants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", c.Param("id")).First(&bill).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bill split not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": bill})
+}
+
+// DeleteBillSplit: Menghapus tagihan bersama. Transaksi pengeluaran bagian
+// user masuk tempat sampah dan talangannya dibatalkan.
+func DeleteBillSplit(c *gin.Context) {
+	var bill models.BillSplit
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&bill).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bill split not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if bill.TransactionID != nil {
+			var transaction models.Transaction
+			err := tx.First(&transaction, *bill.TransactionID).Error
+			if err == nil {
+				if err := ensureNotReconciled(transaction); err != nil {
+					return err
+				}
+				if err := removeTransaction(tx, transaction); err != nil {
+					return err
+				}
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		if err := deleteBillJournal(tx, bill.JournalEntryID); err != nil {
+			return err
+		}
+		if err := tx.Where("bill_split_id = ?", bill.ID).Delete(&models.BillParticipant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&bill).Error
+	})
+	if err != nil {
+		writeBillError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Bill split deleted successfully"})
+}
+
+// GetBillSettlements: Mendapatkan riwayat pelunasan tagihan bersama
+func GetBillSettlements(c *gin.Context) {
+	var settlements []models.BillSettlement
+	db := c.MustGet("db").(*gorm.DB)
+
+	db.Scopes(tenantScope(c)).Order("settled_date desc, id desc").Find(&settlements)
+	c.JSON(http.StatusOK, gin.H{"data": settlements})
+}
+
+// CreateBillSettlement: Mencatat pembayaran pelunasan antar peserta. Bila user
+// sendiri yang membayar atau menerima, uangnya dibukukan di dompet terhadap
+// akun Piutang Patungan.
+func CreateBillSettlement(c *gin.Context) {
+	var input BillSettlementInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	settlement := models.BillSettlement{
+		UserID:      currentUser.ID,
+		HouseholdID: getCurrentHousehold(c).ID,
+		FromName:    strings.TrimSpace(input.From),
+		ToName:      strings.TrimSpace(input.To),
+		Amount:      input.Amount,
+		SettledDate: input.SettledDate,
+	}
+	if strings.EqualFold(settlement.FromName, settlement.ToName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be different people"})
+		return
+	}
+	if len(settlement.FromName) > 100 || len(settlement.ToName) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Names may be at most 100 characters"})
+		return
+	}
+	known, err := billNames(db, settlement.HouseholdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bill splits"})
+		return
+	}
+	settlement.FromName = canonicalBillName(known, settlement.FromName)
+	settlement.ToName = canonicalBillName(known, settlement.ToName)
+	if settlement.FromName == "" {
+		settlement.FromUserID = &currentUser.ID
+	}
+	if settlement.ToName == "" {
+		settlement.ToUserID = &currentUser.ID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if settlement.FromName != "" && settlement.ToName != "" {
+			// Pelunasan antar peserta lain tidak menyentuh dompet user
+			currency, err := billCurrency(input.Currency, nil, currentUser)
+			if err != nil {
+				return err
+			}
+			settlement.Currency = currency
+			return tx.Create(&settlement).Error
+		}
+
+		wallet, err := billWallet(tx, input.WalletID, settlement.HouseholdID, currentUser.ID)
+		if err != nil {
+			return err
+		}
+		settlement.WalletID = &wallet.ID
+		if settlement.Currency, err = billCurrency(input.Currency, &wallet, currentUser); err != nil {
+			return err
+		}
+
+		account, err := walletAccount(tx, wallet)
+		if err != nil {
+			return err
+		}
+		shared, err := sharedBillsAccount(tx, settlement.HouseholdID, currentUser.ID)
+		if err != nil {
+			return err
+		}
+		entry := models.JournalEntry{
+			UserID:      currentUser.ID,
+			HouseholdID: settlement.HouseholdID,
+			Kind:        models.JournalAdjustment,
+			EntryDate:   settlement.SettledDate,
+		}
+		if settlement.FromName == "" {
+			entry.Description = "Pelunasan patungan ke " + settlement.ToName
+			entry.Postings = movePostings(shared.ID, account.ID, settlement.Amount)
+		} else {
+			entry.Description = "Pelunasan patungan dari " + settlement.FromName
+			entry.Postings = movePostings(account.ID, shared.ID, settlement.Amount)
+		}
+		if err := postJournal(tx, &entry); err != nil {
+			return err
+		}
+		settlement.JournalEntryID = &entry.ID
+		return tx.Create(&settlement).Error
+	})
+	if err != nil {
+		writeBillError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": settlement, "message": "Settlement recorded successfully"})
+}
+
+// DeleteBillSettlement: Menghapus pelunasan dan membatalkan efeknya ke dompet
+func DeleteBillSettlement(c *gin.Context) {
+	var settlement models.BillSettlement
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&settlement).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Settlement not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteBillJournal(tx, settlement.JournalEntryID); err != nil {
+			return err
+		}
+		return tx.Delete(&settlement).Error
+	})
+	if err != nil {
+		writeBillError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Settlement deleted successfully"})
+}
+
+// GetBillBalances: Saldo bersih tiap orang dari semua tagihan bersama dan
+// pelunasannya, beserta daftar pembayaran paling sedikit untuk melunasinya,
+// dipisah per mata uang
+func GetBillBalances(c *gin.Context) {
+	var bills []models.BillSplit
+	var settlements []models.BillSettlement
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	db.Scopes(tenantScope(c)).Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).Order("id").Find(&bills)
+	db.Scopes(tenantScope(c)).Order("id").Find(&settlements)
+
+	// Saldo dalam sen per mata uang lalu per pihak. Baris tanpa nama milik
+	// anggota household yang mencatatnya, sehingga saldo tiap anggota sama
+	// dengan akun Piutang Patungan miliknya. Nama orang luar dibandingkan
+	// tanpa membedakan huruf besar-kecil dan ditampilkan dengan ejaan
+	// pertamanya.
+	balances := map[string]map[string]int64{}
+	parties := map[string]billParty{}
+	known := map[string]string{}
+	add := func(currency, name string, userID *uint, creatorID uint, cents int64) {
+		party := billParty{name: canonicalBillName(known, name)}
+		key := "name:" + party.name
+		if name == "" {
+			party = billParty{userID: creatorID}
+			if userID != nil {
+				party.userID = *userID
+			}
+			key = fmt.Sprintf("user:%d", party.userID)
+		}
+		parties[key] = party
+		if balances[currency] == nil {
+			balances[currency] = map[string]int64{}
+		}
+		balances[currency][key] += cents
+	}
+	for _, bill := range bills {
+		add(bill.Currency, bill.PaidBy, bill.PaidByUserID, bill.UserID, toCents(bill.TotalAmount))
+		for _, participant := range bill.Participants {
+			add(bill.Currency, participant.Name, participant.UserID, bill.UserID, -toCents(participant.Amount))
+		}
+	}
+	for _, settlement := range settlements {
+		add(settlement.Currency, settlement.FromName, settlement.FromUserID, settlement.UserID, toCents(settlement.Amount))
+		add(settlement.Currency, settlement.ToName, settlement.ToUserID, settlement.UserID, -toCents(settlement.Amount))
+	}
+
+	// Anggota household lain ditampilkan dengan namanya
+	memberNames := map[uint]string{}
+	var memberIDs []uint
+	for _, party := range parties {
+		if party.userID != 0 && party.userID != currentUser.ID {
+			memberIDs = append(memberIDs, party.userID)
+		}
+	}
+	if len(memberIDs) > 0 {
+		var members []models.User
+		db.Where("id IN ?", memberIDs).Find(&members)
+		for _, member := range members {
+			memberNames[member.ID] = member.Name
+		}
+	}
+	describe := func(key string) (string, *uint) {
+		party := parties[key]
+		if party.userID == 0 {
+			return party.name, nil
+		}
+		userID := party.userID
+		return memberNames[userID], &userID
+	}
+
+	currencies := make([]string, 0, len(balances))
+	for currency := range balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	summaries := make([]BillBalanceSummary, 0, len(currencies))
+	for _, currency := range currencies {
+		summary := BillBalanceSummary{Currency: currency, Balances: []BillBalance{}, SettleUp: []BillSettlePayment{}}
+		for key, cents := range balances[currency] {
+			if cents != 0 {
+				name, userID := describe(key)
+				summary.Balances = append(summary.Balances, BillBalance{Name: name, UserID: userID, Balance: float64(cents) / 100})
+			}
+		}
+		sort.Slice(summary.Balances, func(i, j int) bool {
+			a, b := summary.Balances[i], summary.Balances[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.UserID != nil && (b.UserID == nil || *a.UserID < *b.UserID)
+		})
+		for _, payment := range utils.SettleUp(balances[currency]) {
+			from, fromUserID := describe(payment.From)
+			to, toUserID := describe(payment.To)
+			summary.SettleUp = append(summary.SettleUp, BillSettlePayment{
+				From: from, FromUserID: fromUserID, To: to, ToUserID: toUserID, Amount: float64(payment.Amount) / 100,
+			})
+		}
+		summaries = append(summaries, summary)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summaries})
+}
@@ -1,8 +1,14 @@
 package controllers
 
 import (
+	"dompet/backend/database"
 	"dompet/backend/models"
+	"errors"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -11,6 +17,112 @@ import (
 type CategoryInput struct {
 	Name string `json:"name" binding:"required"`
 	Type string `json:"type" binding:"required,oneof=income expense"`
+	// ParentID menjadikan kategori ini subkategori. Saat update, nilai 0
+	// memindahkannya kembali menjadi kategori utama.
+	ParentID *uint `json:"parent_id"`
+	// Field tampilan bersifat opsional; saat update, field yang tidak dikirim
+	// tidak diubah. String kosong menghapus ikon atau warna.
+	Icon      *string `json:"icon"`
+	Color     *string `json:"color"`
+	SortOrder *int    `json:"sort_order" binding:"omitempty,min=0"`
+	Archived  *bool   `json:"archived"`
+}
+
+var (
+	categoryIconPattern  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	categoryColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+// errCategoryReconciled dikembalikan bila kategori yang digabung masih dipakai
+// transaksi yang sudah direkonsiliasi
+var errCategoryReconciled = errors.New("cannot merge a category with reconciled transactions; unlock them first")
+
+// applyCategoryAppearance memvalidasi lalu menerapkan field tampilan dari
+// input ke kategori
+func applyCategoryAppearance(category *models.Category, input CategoryInput) error {
+	if input.Icon != nil {
+		if *input.Icon != "" && (len(*input.Icon) > 50 || !categoryIconPattern.MatchString(*input.Icon)) {
+			return errors.New("icon must be a lowercase key such as \"shopping-cart\" (max 50 characters)")
+		}
+		category.Icon = *input.Icon
+	}
+	if input.Color != nil {
+		if *input.Color != "" && !categoryColorPattern.MatchString(*input.Color) {
+			return errors.New("color must be a hex colour such as \"#FF8800\"")
+		}
+		category.Color = strings.ToUpper(*input.Color)
+	}
+	if input.SortOrder != nil {
+		category.SortOrder = *input.SortOrder
+	}
+	if input.Archived != nil {
+		switch {
+		case *input.Archived && category.ArchivedAt == nil:
+			now := time.Now()
+			category.ArchivedAt = &now
+		case !*input.Archived:
+			category.ArchivedAt = nil
+		}
+	}
+	return nil
+}
+
+// validateCategoryParent memastikan induk berada di household yang sama,
+// bertipe sama, dan tidak membentuk siklus (induk bukan turunan kategori itu)
+func validateCategoryParent(db *gorm.DB, category models.Category, parentID uint) error {
+	var parent models.Category
+	if err := db.Scopes(householdScope(category.HouseholdID)).First(&parent, parentID).Error; err != nil {
+		return errors.New("parent category not found")
+	}
+	if parent.Type != category.Type {
+		return errors.New("a subcategory must have the same type as its parent")
+	}
+
+	visited := map[uint]bool{}
+	for current := &parent; current != nil; {
+		if category.ID != 0 && current.ID == category.ID {
+			return errors.New("a category cannot be moved under itself or its subcategories")
+		}
+		if visited[current.ID] || current.ParentID == nil {
+			break
+		}
+		visited[current.ID] = true
+
+		var next models.Category
+		if err := db.First(&next, *current.ParentID).Error; err != nil {
+			break
+		}
+		current = &next
+	}
+	return nil
+}
+
+// buildCategoryTree menyusun daftar kategori datar menjadi pohon
+func buildCategoryTree(categories []models.Category) []models.Category {
+	children := map[uint][]models.Category{}
+	ids := map[uint]bool{}
+	for _, category := range categories {
+		ids[category.ID] = true
+	}
+
+	var roots []models.Category
+	for _, category := range categories {
+		// Kategori dengan induk yang tidak ikut dimuat diperlakukan sebagai akar
+		if category.ParentID != nil && ids[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots)
 }
 
 // CreateCategory: Membuat kategori baru
@@ -19,15 +131,39 @@ func CreateCategory(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	currentUser, _ := getCurrentUser(c) // Kita gunakan lagi helper dari wallet_controller
 
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	category := models.Category{
-		Name:   input.Name,
-		Type:   input.Type,
-		UserID: currentUser.ID,
+		Name:        input.Name,
+		Type:        input.Type,
+		UserID:      currentUser.ID,
+		HouseholdID: getCurrentHousehold(c).ID,
+	}
+
+	if input.ParentID != nil && *input.ParentID != 0 {
+		if err := validateCategoryParent(db, category, *input.ParentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		category.ParentID = input.ParentID
+	}
+
+	if err := applyCategoryAppearance(&category, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Tanpa sort_order, kategori baru diletakkan paling akhir
+	if input.SortOrder == nil {
+		var last struct{ Max int }
+		db.Model(&models.Category{}).Scopes(tenantScope(c)).Select("COALESCE(MAX(sort_order), -1) AS max").Scan(&last)
+		category.SortOrder = last.Max + 1
 	}
 
 	if err := db.Create(&category).Error; err != nil {
@@ -38,24 +174,47 @@ func CreateCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": category})
 }
 
-// GetAllCategories: Mendapatkan semua kategori milik user
+// GetAllCategories: Mendapatkan semua kategori household aktif. Dengan
+// ?wallet_id=ID, yang dikembalikan adalah kategori household pemilik dompet
+// tersebut (untuk mencatat transaksi di dompet bersama). Dengan ?tree=true,
+// subkategori disusun di dalam children induknya. Kategori yang diarsipkan
+// disembunyikan kecuali dengan ?include_archived=true.
 func GetAllCategories(c *gin.Context) {
 	var categories []models.Category
 	db := c.MustGet("db").(*gorm.DB)
-	currentUser, _ := getCurrentUser(c)
 
-	db.Where("user_id = ?", currentUser.ID).Find(&categories)
+	scope := tenantScope(c)
+	if walletID := c.Query("wallet_id"); walletID != "" {
+		wallet, ok := loadWalletWithRole(c, db, walletID, models.RoleViewer)
+		if !ok {
+			return
+		}
+		scope = householdScope(wallet.HouseholdID)
+	}
 
+	query := db.Scopes(scope)
+	if c.Query("include_archived") != "true" {
+		query = query.Where("archived_at IS NULL")
+	}
+	query.Order("sort_order, name").Find(&categories)
+
+	if c.Query("tree") == "true" {
+		c.JSON(http.StatusOK, gin.H{"data": buildCategoryTree(categories)})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"data": categories})
 }
 
 func UpdateCategory(c *gin.Context) {
 	var input CategoryInput
 	db := c.MustGet("db").(*gorm.DB)
-	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
 
 	var category models.Category
-	if err := db.Where("id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&category).Error; err != nil {
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&category).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
 		return
 	}
@@ -65,32 +224,392 @@ func UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	db.Model(&category).Updates(input)
-	c.JSON(http.StatusOK, gin.H{"data": category})
+	updated := category
+	updated.Name = input.Name
+	updated.Type = input.Type
+	if input.ParentID != nil {
+		updated.ParentID = input.ParentID
+		if *input.ParentID == 0 {
+			updated.ParentID = nil
+		}
+	}
+
+	// Tipe harus tetap sama dengan induk dan semua subkategorinya
+	if updated.ParentID != nil {
+		if err := validateCategoryParent(db, updated, *updated.ParentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := applyCategoryAppearance(&updated, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if updated.Type != category.Type {
+		var children int64
+		db.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children)
+		if children > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change the type of a category that has subcategories"})
+			return
+		}
+		// Semua baris rincian satu transaksi harus bertipe sama
+		var splits int64
+		db.Model(&models.TransactionSplit{}).Where("category_id = ?", category.ID).Count(&splits)
+		if splits > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change the type of a category used in split transactions"})
+			return
+		}
+		// Mengubah tipe membalik efek transaksi pada saldo dompet
+		var reconciled int64
+		db.Unscoped().Model(&models.Transaction{}).Where("category_id = ? AND status = ?", category.ID, models.TransactionReconciled).Count(&reconciled)
+		if reconciled > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot change the type of a category with reconciled transactions"})
+			return
+		}
+		// Refund hanya berlaku untuk pengeluaran
+		var refunds int64
+		db.Unscoped().Model(&models.Transaction{}).Where("category_id = ? AND refund_of_id IS NOT NULL", category.ID).Count(&refunds)
+		if refunds > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot change the type of a category with refunds"})
+			return
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if updated.Type != category.Type {
+			if err := changeCategoryType(tx, category, updated.Type); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&category).Select("Name", "Type", "ParentID", "Icon", "Color", "SortOrder", "ArchivedAt").Updates(&updated).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Account{}).Where("category_id = ?", category.ID).Update("name", updated.Name).Error
+	})
+	if err != nil {
+		if isWalletRuleError(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+type ReorderCategoriesInput struct {
+	CategoryIDs []uint `json:"category_ids" binding:"required,min=1"`
+}
+
+// ReorderCategories: Mengatur ulang urutan kategori sekaligus. Urutan
+// category_ids menjadi sort_order baru (0, 1, 2, ...).
+func ReorderCategories(c *gin.Context) {
+	var input ReorderCategoriesInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	seen := map[uint]bool{}
+	for _, id := range input.CategoryIDs {
+		if seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "category_ids must not contain duplicates"})
+			return
+		}
+		seen[id] = true
+	}
+
+	var found int64
+	db.Model(&models.Category{}).Scopes(tenantScope(c)).Where("id IN ?", input.CategoryIDs).Count(&found)
+	if int(found) != len(input.CategoryIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "One or more categories were not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for order, id := range input.CategoryIDs {
+			if err := tx.Model(&models.Category{}).Where("id = ?", id).Update("sort_order", order).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder categories"})
+		return
+	}
+
+	var categories []models.Category
+	db.Scopes(tenantScope(c)).Where("id IN ?", input.CategoryIDs).Order("sort_order").Find(&categories)
+	c.JSON(http.StatusOK, gin.H{"data": categories})
 }
 
-// DeleteCategory: Menghapus kategori
+// changeCategoryType mengubah tipe kategori beserta semua transaksinya.
+// Setiap transaksi dibukukan ulang sehingga saldo dompet ikut terkoreksi
+// (pemasukan menjadi pengeluaran atau sebaliknya). Transaksi di tempat sampah
+// hanya diganti tipenya; jurnalnya dibukukan ulang saat dipulihkan.
+func changeCategoryType(tx *gorm.DB, category models.Category, newType string) error {
+	var transactions []models.Transaction
+	// Kategori yang punya refund tidak bisa berganti tipe (lihat UpdateCategory)
+	if err := tx.Unscoped().Where("category_id = ? AND refund_of_id IS NULL", category.ID).Find(&transactions).Error; err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		trashed := transaction.DeletedAt.Valid
+		if !trashed {
+			if err := unpostTransaction(tx, transaction); err != nil {
+				return err
+			}
+		}
+		transaction.Type = newType
+		if err := tx.Unscoped().Model(&transaction).Updates(map[string]interface{}{"type": newType, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+		if trashed {
+			continue
+		}
+		if err := postTransaction(tx, transaction); err != nil {
+			return err
+		}
+	}
+
+	accountType := models.AccountExpense
+	if newType == "income" {
+		accountType = models.AccountIncome
+	}
+	return tx.Model(&models.Account{}).Where("category_id = ?", category.ID).Update("type", accountType).Error
+}
+
+// isCategoryDescendant bernilai true bila ancestorID adalah salah satu induk
+// (langsung maupun tidak langsung) dari category
+func isCategoryDescendant(db *gorm.DB, category models.Category, ancestorID uint) bool {
+	visited := map[uint]bool{}
+	for parentID := category.ParentID; parentID != nil && !visited[*parentID]; {
+		if *parentID == ancestorID {
+			return true
+		}
+		visited[*parentID] = true
+
+		var parent models.Category
+		if err := db.First(&parent, *parentID).Error; err != nil {
+			return false
+		}
+		parentID = parent.ParentID
+	}
+	return false
+}
+
+// mergeCategory memindahkan semua transaksi (termasuk baris rincian),
+// posting, aturan, dan subkategori dari source ke target, lalu menghapus
+// source. Kedua kategori harus bertipe sama sehingga saldo dompet tidak
+// berubah. Transaksi yang sudah direkonsiliasi tidak boleh ikut berubah.
+func mergeCategory(tx *gorm.DB, source, target models.Category) error {
+	var reconciled int64
+	if err := tx.Unscoped().Model(&models.Transaction{}).
+		Where("status = ? AND (category_id = ? OR id IN (?))", models.TransactionReconciled, source.ID,
+			tx.Model(&models.TransactionSplit{}).Select("transaction_id").Where("category_id = ?", source.ID)).
+		Count(&reconciled).Error; err != nil {
+		return err
+	}
+	if reconciled > 0 {
+		return errCategoryReconciled
+	}
+
+	sourceAcc, err := categoryAccount(tx, source)
+	if err != nil {
+		return err
+	}
+	targetAcc, err := categoryAccount(tx, target)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Posting{}).Where("account_id = ?", sourceAcc.ID).Update("account_id", targetAcc.ID).Error; err != nil {
+		return err
+	}
+	// Transaksi di tempat sampah ikut dipindahkan agar tetap bisa dipulihkan
+	if err := tx.Unscoped().Model(&models.Transaction{}).Where("category_id = ?", source.ID).
+		Updates(map[string]interface{}{"category_id": target.ID, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.TransactionSplit{}).Where("category_id = ?", source.ID).Update("category_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.TransactionRule{}).Where("set_category_id = ?", source.ID).Update("set_category_id", target.ID).Error; err != nil {
+		return err
+	}
+	// Statistik saran kategori user yang terdampak dihapus agar dilatih ulang
+	// dari riwayat yang sudah digabung
+	affectedUsers := tx.Model(&models.CategoryDocStat{}).Select("user_id").Where("category_id = ?", source.ID)
+	if err := tx.Where("user_id IN (?)", affectedUsers).Delete(&models.CategoryTokenStat{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id IN (?)", affectedUsers).Delete(&models.CategoryDocStat{}).Error; err != nil {
+		return err
+	}
+
+	// Bila target adalah turunan source, target naik ke posisi source dulu
+	// agar pemindahan subkategori tidak membentuk siklus
+	if isCategoryDescendant(tx, target, source.ID) {
+		if err := tx.Model(&target).Update("parent_id", source.ParentID).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&models.Category{}).Where("parent_id = ? AND id <> ?", source.ID, target.ID).Update("parent_id", target.ID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Delete(&sourceAcc).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&source).Error
+}
+
+// findMergeTarget mencari kategori target penggabungan di household aktif
+func findMergeTarget(c *gin.Context, db *gorm.DB, source models.Category, targetID string) (models.Category, bool) {
+	var target models.Category
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", targetID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target category not found"})
+		return target, false
+	}
+	if target.ID == source.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge a category into itself"})
+		return target, false
+	}
+	if target.Type != source.Type {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Categories can only be merged into a category of the same type"})
+		return target, false
+	}
+	return target, true
+}
+
+type MergeCategoryInput struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+// MergeCategory: Menggabungkan kategori ke kategori lain. Semua transaksi dan
+// subkategori pindah ke target, lalu kategori asal dihapus.
+func MergeCategory(c *gin.Context) {
+	var input MergeCategoryInput
+	var source models.Category
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, ok := findMergeTarget(c, db, source, strconv.FormatUint(uint64(input.TargetID), 10))
+	if !ok {
+		return
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error { return mergeCategory(tx, source, target) }); err != nil {
+		if errors.Is(err, errCategoryReconciled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge category"})
+		return
+	}
+
+	db.First(&target, target.ID)
+	c.JSON(http.StatusOK, gin.H{"data": target, "message": "Category merged successfully"})
+}
+
+// DeleteCategory: Menghapus kategori. Dengan ?reassign_to=ID, transaksi
+// kategori dipindahkan dulu ke kategori ID (sama dengan merge).
 func DeleteCategory(c *gin.Context) {
 	var category models.Category
 	db := c.MustGet("db").(*gorm.DB)
-	currentUser, _ := getCurrentUser(c)
 
-	if err := db.Where("id = ?", c.Param("id")).First(&category).Error; err != nil {
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&category).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
 		return
 	}
 
-	if category.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this category"})
+	if reassignTo := c.Query("reassign_to"); reassignTo != "" {
+		target, ok := findMergeTarget(c, db, category, reassignTo)
+		if !ok {
+			return
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error { return mergeCategory(tx, category, target) }); err != nil {
+			if errors.Is(err, errCategoryReconciled) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": true, "message": "Category deleted and transactions reassigned"})
+		return
+	}
+
+	// Kategori yang masih dipakai transaksi aktif tidak bisa dihapus
+	var used int64
+	db.Model(&models.Transaction{}).Where("category_id = ?", category.ID).Count(&used)
+	if used == 0 {
+		db.Model(&models.TransactionSplit{}).
+			Where("category_id = ? AND transaction_id IN (?)", category.ID, db.Model(&models.Transaction{}).Select("id")).
+			Count(&used)
+	}
+	if used > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot delete category, it is already in use by transactions. Use reassign_to to move them to another category."})
+		return
+	}
+
+	// Kategori masuk tempat sampah. Subkategorinya naik satu tingkat ke induk
+	// kategori ini.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", category.ID).Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TransactionRule{}).Where("set_category_id = ?", category.ID).Update("set_category_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Category moved to trash"})
+}
+
+// RestoreDefaultCategories: Menambahkan kembali kategori bawaan yang belum ada
+// di household aktif, dengan nama sesuai bahasa user. Kategori yang sudah ada
+// tidak diduplikasi.
+func RestoreDefaultCategories(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
 		return
 	}
 
-	// Coba hapus
-	if err := db.Delete(&category).Error; err != nil {
-		// Ini akan terjadi jika kategori sudah digunakan di transaksi (karena ON DELETE RESTRICT)
-		c.JSON(http.StatusConflict, gin.H{"error": "Cannot delete category, it is already in use by transactions."})
+	added, err := database.SeedDefaultCategories(db, getCurrentHousehold(c).ID, currentUser.ID, currentUser.Language)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore default categories"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Category deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": added, "message": "Default categories restored"})
 }
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errVersionConflict dikembalikan bila data sudah diubah request lain sejak
+// dibaca
+var errVersionConflict = errors.New("resource was modified by another request; reload and try again")
+
+// etagFor membentuk ETag dari kolom version
+func etagFor(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// checkIfMatch mencocokkan header If-Match dengan versi data saat ini. Tanpa
+// header (atau "*") request selalu diteruskan. Bila tidak cocok, respons 412
+// langsung ditulis dan hasilnya false.
+func checkIfMatch(c *gin.Context, version uint) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etagFor(version) {
+			return true
+		}
+	}
+	c.Header("ETag", etagFor(version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Resource has been modified; reload and try again", "version": version})
+	return false
+}
+
+// bumpVersion menaikkan version hanya bila masih sama dengan yang dibaca,
+// sehingga dua perubahan bersamaan tidak saling menimpa. Baris ikut terkunci
+// sampai database transaction selesai.
+func bumpVersion(tx *gorm.DB, model interface{}, id, version uint) error {
+	result := tx.Model(model).Where("id = ? AND version = ?", id, version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: true},
+		{header: "*", want: true},
+		{header: `"3"`, want: true},
+		{header: `W/"3"`, want: true},
+		{header: ` "2", "3" `, want: true},
+		{header: `"2"`, want: false},
+		{header: `3`, want: false},
+		{header: `"30"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			c.Request.Header.Set("If-Match", tt.header)
+
+			if got := checkIfMatch(c, 3); got != tt.want {
+				t.Fatalf("checkIfMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+			if tt.want {
+				return
+			}
+			if w.Code != http.StatusPreconditionFailed {
+				t.Errorf("status = %d, want 412", w.Code)
+			}
+			if got := w.Header().Get("ETag"); got != `"3"` {
+				t.Errorf("ETag = %s, want \"3\"", got)
+			}
+		})
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	f := newLedgerFixture(t)
+	wallet := f.wallet(t, models.Wallet{Name: "Tunai"}, 0)
+
+	if err := bumpVersion(f.db, &models.Wallet{}, wallet.ID, 1); err != nil {
+		t.Fatalf("bumpVersion(1) error = %v", err)
+	}
+	// Versi 1 sudah basi setelah dinaikkan
+	if err := bumpVersion(f.db, &models.Wallet{}, wallet.ID, 1); !errors.Is(err, errVersionConflict) {
+		t.Fatalf("bumpVersion(1) again error = %v, want errVersionConflict", err)
+	}
+	if err := bumpVersion(f.db, &models.Wallet{}, wallet.ID, 2); err != nil {
+		t.Fatalf("bumpVersion(2) error = %v", err)
+	}
+	f.db.First(&wallet, wallet.ID)
+	if wallet.Version != 3 {
+		t.Errorf("version = %d, want 3", wallet.Version)
+	}
+}
+
+// concurrentWrite menaikkan version baris setelah handler membacanya,
+// seolah-olah request lain mengubah data di antara baca dan tulis
+func concurrentWrite(t *testing.T, db *gorm.DB, table string, id uint) {
+	fired := false
+	err := db.Callback().Query().After("gorm:query").Register("test:concurrent_write", func(tx *gorm.DB) {
+		if fired || tx.Statement.Table != table {
+			return
+		}
+		fired = true
+		tx.Session(&gorm.Session{NewDB: true}).Exec("UPDATE "+table+" SET version = version + 1 WHERE id = ?", id)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateWalletIfMatch(t *testing.T) {
+	f := newLedgerFixture(t)
+	wallet := f.wallet(t, models.Wallet{Name: "Tunai"}, 0)
+
+	update := func(ifMatch, name string) *httptest.ResponseRecorder {
+		req := jsonRequest(t, http.MethodPut, fmt.Sprintf("/api/wallets/%d", wallet.ID), gin.H{"name": name})
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return f.serve(UpdateWallet, req, idParam(wallet.ID))
+	}
+
+	w := update(`"1"`, "Dompet Harian")
+	if w.Code != http.StatusOK {
+		t.Fatalf("update with current ETag status = %d, body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag after update = %s, want \"2\"", got)
+	}
+
+	w = update(`"1"`, "Dompet Lama")
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("update with stale ETag status = %d, want 412", w.Code)
+	}
+	if got := w.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("412 ETag = %s, want the current \"2\"", got)
+	}
+	f.db.First(&wallet, wallet.ID)
+	if wallet.Name != "Dompet Harian" {
+		t.Errorf("name = %q, the stale update must not be applied", wallet.Name)
+	}
+
+	// Perubahan lain yang masuk setelah dompet dibaca ditolak 409
+	concurrentWrite(t, f.db, "wallets", wallet.ID)
+	if w := update("", "Dompet Baru"); w.Code != http.StatusConflict {
+		t.Fatalf("update racing another write status = %d, want 409", w.Code)
+	}
+	f.db.First(&wallet, wallet.ID)
+	if wallet.Name != "Dompet Harian" || wallet.Version != 3 {
+		t.Errorf("wallet = %q version %d, want the racing write only (version 3)", wallet.Name, wallet.Version)
+	}
+}
+
+func TestUpdateTransactionIfMatch(t *testing.T) {
+	f := newLedgerFixture(t)
+	wallet := f.wallet(t, models.Wallet{Name: "Tunai"}, 1000)
+	food := f.category(t, "Makan", "expense")
+	transaction := f.transaction(t, wallet, food, 100)
+
+	update := func(ifMatch string, amount float64) *httptest.ResponseRecorder {
+		req := jsonRequest(t, http.MethodPut, fmt.Sprintf("/api/transactions/%d", transaction.ID), gin.H{
+			"wallet_id":        wallet.ID,
+			"category_id":      food.ID,
+			"amount":           amount,
+			"transaction_date": time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC),
+		})
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return f.serve(UpdateTransaction, req, idParam(transaction.ID))
+	}
+
+	if w := update(`"2"`, 150); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("update with a future ETag status = %d, want 412", w.Code)
+	}
+	w := update(`W/"1"`, 150)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag = %s, want \"2\"", got)
+	}
+	if got := f.balance(t, wallet); got != 850 {
+		t.Errorf("balance = %.2f, want 850", got)
+	}
+
+	concurrentWrite(t, f.db, "transactions", transaction.ID)
+	if w := update("", 400); w.Code != http.StatusConflict {
+		t.Fatalf("update racing another write status = %d, want 409", w.Code)
+	}
+	// Perubahan yang ditolak tidak menyentuh buku besar
+	if got := f.balance(t, wallet); got != 850 {
+		t.Errorf("balance after 409 = %.2f, want 850", got)
+	}
+}
@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DebtInput struct {
+	Counterparty string  `json:"counterparty" binding:"required,max=100"`
+	Direction    string  `json:"direction" binding:"required,oneof=payable receivable"`
+	Principal    float64 `json:"principal" binding:"required,gt=0"`
+	Currency     string  `json:"currency" binding:"omitempty,max=5"`
+	Description  string  `json:"description"`
+	StartDate    string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	DueDate      *string `json:"due_date"`                      // YYYY-MM-DD, boleh kosong
+}
+
+var (
+	// errInvalidDebt dikembalikan bila transaksi ditautkan ke utang-piutang
+	// yang tidak valid
+	errInvalidDebt = errors.New("invalid debt")
+	// errDebtOverpaid dikembalikan bila cicilan melebihi sisa utang-piutang
+	errDebtOverpaid = errors.New("repayment exceeds the outstanding balance of the debt")
+)
+
+// debtRepaid menghitung total cicilan per utang-piutang dari transaksi aktif
+// yang ditautkan. exceptTransactionID tidak ikut dihitung (0 untuk semua).
+func debtRepaid(db *gorm.DB, debtIDs []uint, exceptTransactionID uint) (map[uint]float64, error) {
+	type repaidRow struct {
+		DebtID uint
+		Total  float64
+	}
+	var rows []repaidRow
+	err := db.Table("transactions").
+		Select("transactions.debt_id, SUM(transactions.amount) AS total").
+		Joins("JOIN debts ON debts.id = transactions.debt_id").
+		Where("transactions.debt_id IN ? AND transactions.id <> ? AND transactions.deleted_at IS NULL", debtIDs, exceptTransactionID).
+		Where("transactions.type = CASE debts.direction WHEN ? THEN 'expense' ELSE 'income' END", models.DebtPayable).
+		Group("transactions.debt_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	repaid := map[uint]float64{}
+	for _, row := range rows {
+		repaid[row.DebtID] = row.Total
+	}
+	return repaid, nil
+}
+
+// withDebtBalances mengisi sisa, status dan keterlambatan utang-piutang
+func withDebtBalances(db *gorm.DB, debts []models.Debt, today time.Time) error {
+	if len(debts) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(debts))
+	for _, debt := range debts {
+		ids = append(ids, debt.ID)
+	}
+	repaid, err := debtRepaid(db, ids, 0)
+	if err != nil {
+		return err
+	}
+
+	for i := range debts {
+		debt := &debts[i]
+		debt.Repaid = repaid[debt.ID]
+		debt.Outstanding = float64(toCents(debt.Principal)-toCents(debt.Repaid)) / 100
+		debt.Status = models.DebtOpen
+		if debt.Outstanding <= 0 {
+			debt.Status = models.DebtSettled
+		}
+		debt.Overdue = debt.Status == models.DebtOpen && debt.DueDate != nil && debt.DueDate.Before(today)
+	}
+	return nil
+}
+
+// checkDebtLink memastikan transaksi boleh ditautkan ke utang-piutang: harus
+// satu household dan satu mata uang dengan dompetnya, dan cicilannya tidak
+// melebihi sisa utang-piutang. Baris utang-piutang dikunci agar cicilan yang
+// dicatat bersamaan tidak sama-sama lolos pemeriksaan sisa utang.
+func checkDebtLink(tx *gorm.DB, debtID uint, wallet models.Wallet, transaction models.Transaction) error {
+	var debt models.Debt
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(householdScope(wallet.HouseholdID)).First(&debt, debtID).Error; err != nil {
+		return fmt.Errorf("%w: debt %d not found in the household of the wallet", errInvalidDebt, debtID)
+	}
+	if debt.Currency != wallet.Currency {
+		return fmt.Errorf("%w: the wallet currency must match the debt currency", errInvalidDebt)
+	}
+	if transaction.Type != debt.RepaymentType() {
+		return nil
+	}
+
+	repaid, err := debtRepaid(tx, []uint{debt.ID}, transaction.ID)
+	if err != nil {
+		return err
+	}
+	if toCents(repaid[debt.ID])+toCents(transaction.Amount) > toCents(debt.Principal) {
+		return errDebtOverpaid
+	}
+	return nil
+}
+
+// applyDebtInput memvalidasi input dan menyalinnya ke utang-piutang
+func applyDebtInput(debt *models.Debt, input DebtInput, user models.User) error {
+	startDate, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		return errors.New("start_date must be in YYYY-MM-DD format")
+	}
+	debt.DueDate = nil
+	if input.DueDate != nil && *input.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", *input.DueDate)
+		if err != nil {
+			return errors.New("due_date must be in YYYY-MM-DD format")
+		}
+		if dueDate.Before(startDate) {
+			return errors.New("due_date cannot be before start_date")
+		}
+		debt.DueDate = &dueDate
+	}
+
+	currency := strings.ToUpper(input.Currency)
+	if currency == "" {
+		currency = user.Currency
+	}
+
+	debt.Counterparty = strings.TrimSpace(input.Counterparty)
+	debt.Direction = input.Direction
+	debt.Principal = input.Principal
+	debt.Currency = currency
+	debt.Description = input.Description
+	debt.StartDate = startDate
+	return nil
+}
+
+// loadDebt mencari utang-piutang di household aktif berdasarkan parameter URL
+func loadDebt(c *gin.Context, db *gorm.DB) (models.Debt, bool) {
+	var debt models.Debt
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&debt).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Debt not found"})
+		return debt, false
+	}
+	return debt, true
+}
+
+// GetAllDebts: Mendapatkan hutang dan piutang household aktif beserta sisanya.
+// Bisa difilter dengan ?direction=payable|receivable dan ?status=open|settled.
+func GetAllDebts(c *gin.Context) {
+	var debts []models.Debt
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	direction := c.Query("direction")
+	if direction != "" && direction != models.DebtPayable && direction != models.DebtReceivable {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be payable or receivable"})
+		return
+	}
+	status := c.Query("status")
+	if status != "" && status != models.DebtOpen && status != models.DebtSettled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open or settled"})
+		return
+	}
+
+	query := db.Scopes(tenantScope(c)).Order("due_date IS NULL, due_date, start_date, id")
+	if direction != "" {
+		query = query.Where("direction = ?", direction)
+	}
+	query.Find(&debts)
+
+	if err := withDebtBalances(db, debts, userToday(currentUser)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate debt balances"})
+		return
+	}
+
+	result := make([]models.Debt, 0, len(debts))
+	for _, debt := range debts {
+		if status == "" || debt.Status == status {
+			result = append(result, debt)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// GetOverdueDebts: Hutang dan piutang yang belum lunas dan sudah lewat jatuh
+// tempo, diurutkan dari yang paling lama terlambat
+func GetOverdueDebts(c *gin.Context) {
+	var debts []models.Debt
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	today := userToday(currentUser)
+	db.Scopes(tenantScope(c)).Where("due_date < ?", today).Order("due_date, id").Find(&debts)
+
+	if err := withDebtBalances(db, debts, today); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate debt balances"})
+		return
+	}
+
+	overdue := []models.Debt{}
+	for _, debt := range debts {
+		if debt.Overdue {
+			overdue = append(overdue, debt)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": overdue})
+}
+
+// CreateDebt: Mencatat hutang atau piutang baru
+func CreateDebt(c *gin.Context) {
+	var input DebtInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	debt := models.Debt{UserID: currentUser.ID, HouseholdID: getCurrentHousehold(c).ID}
+	if err := applyDebtInput(&debt, input, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Create(&debt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create debt"})
+		return
+	}
+
+	debts := []models.Debt{debt}
+	withDebtBalances(db, debts, userToday(currentUser))
+	c.JSON(http.StatusOK, gin.H{"data": debts[0]})
+}
+
+// GetDebtByID: Mendapatkan satu hutang/piutang beserta transaksi cicilannya
+func GetDebtByID(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	debt, ok := loadDebt(c, db)
+	if !ok {
+		return
+	}
+	db.Preload("Transactions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("transaction_date, id")
+	}).Preload("Transactions.Wallet").Preload("Transactions.Category").First(&debt, debt.ID)
+
+	debts := []models.Debt{debt}
+	if err := withDebtBalances(db, debts, userToday(currentUser)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate debt balances"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": debts[0]})
+}
+
+// UpdateDebt: Mengubah hutang/piutang. Arah dan mata uang tidak bisa diubah
+// bila sudah ada transaksi yang ditautkan, dan pokok tidak boleh lebih kecil
+// dari total cicilan.
+func UpdateDebt(c *gin.Context) {
+	var input DebtInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	debt, ok := loadDebt(c, db)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := debt
+	if err := applyDebtInput(&debt, input, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var linked int64
+	db.Model(&models.Transaction{}).Where("debt_id = ?", debt.ID).Count(&linked)
+	if linked > 0 && (debt.Direction != previous.Direction || debt.Currency != previous.Currency) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot change the direction or currency of a debt that has linked transactions"})
+		return
+	}
+	repaid, err := debtRepaid(db, []uint{debt.ID}, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate debt balances"})
+		return
+	}
+	if toCents(debt.Principal) < toCents(repaid[debt.ID]) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "principal cannot be less than the amount already repaid"})
+		return
+	}
+
+	err = db.Model(&debt).Select("counterparty", "direction", "principal", "currency", "description", "start_date", "due_date").Updates(&debt).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update debt"})
+		return
+	}
+
+	debts := []models.Debt{debt}
+	withDebtBalances(db, debts, userToday(currentUser))
+	c.JSON(http.StatusOK, gin.H{"data": debts[0]})
+}
+
+// DeleteDebt: Menghapus hutang/piutang. Transaksi cicilannya tidak ikut
+// terhapus, hanya dilepas tautannya.
+func DeleteDebt(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	debt, ok := loadDebt(c, db)
+	if !ok {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Transaksi di tempat sampah juga dilepas agar tidak menaut ke utang yang sudah tidak ada
+		if err := tx.Unscoped().Model(&models.Transaction{}).Where("debt_id = ?", debt.ID).
+			Updates(map[string]interface{}{"debt_id": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&debt).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete debt"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Debt deleted successfully"})
+}
@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"dompet/backend/utils"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// duplicateSimilarity adalah batas minimal kemiripan deskripsi agar dua
+// transaksi dianggap duplikat
+const duplicateSimilarity = 0.5
+
+// defaultDuplicateWindow adalah selisih hari maksimal antar duplikat bila
+// DUPLICATE_WINDOW_DAYS tidak diatur
+const defaultDuplicateWindow = 3
+
+// defaultDuplicateLookback adalah jumlah hari ke belakang yang dipindai
+// GetDuplicateTransactions bila ?since= tidak diisi
+const defaultDuplicateLookback = 90
+
+// duplicateWindowDays membaca selisih hari maksimal dari DUPLICATE_WINDOW_DAYS
+func duplicateWindowDays() int {
+	if days, err := strconv.Atoi(os.Getenv("DUPLICATE_WINDOW_DAYS")); err == nil && days >= 0 {
+		return days
+	}
+	return defaultDuplicateWindow
+}
+
+// duplicatePair adalah kunci pasangan transaksi dengan ID kecil di depan
+func duplicatePair(a, b uint) [2]uint {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]uint{a, b}
+}
+
+// isLikelyDuplicate bernilai true bila dua transaksi ada di dompet yang sama,
+// bertipe dan bernominal sama, berjarak paling lama days hari dan
+// deskripsinya mirip
+func isLikelyDuplicate(a, b models.Transaction, days int) bool {
+	if a.WalletID != b.WalletID || a.Type != b.Type || toCents(a.Amount) != toCents(b.Amount) {
+		return false
+	}
+	gap := a.TransactionDate.Sub(b.TransactionDate)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > time.Duration(days)*24*time.Hour {
+		return false
+	}
+	return utils.DescriptionSimilarity(a.Description, b.Description) >= duplicateSimilarity
+}
+
+// dismissedPairs memuat pasangan yang sudah ditandai bukan duplikat untuk
+// transaksi-transaksi yang diberikan. ids bisa berupa daftar ID atau subquery
+// ID transaksi, sehingga pemindaian besar tidak perlu mengirim semua ID.
+func dismissedPairs(db *gorm.DB, ids interface{}) (map[[2]uint]bool, error) {
+	dismissed := map[[2]uint]bool{}
+	var rows []models.DuplicateDismissal
+	if err := db.Where("transaction_id IN (?) OR other_transaction_id IN (?)", ids, ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		dismissed[duplicatePair(row.TransactionID, row.OtherTransactionID)] = true
+	}
+	return dismissed, nil
+}
+
+// findDuplicates mencari transaksi lain yang kemungkinan duplikat dari transaction
+func findDuplicates(db *gorm.DB, transaction models.Transaction, days int) ([]models.Transaction, error) {
+	var candidates []models.Transaction
+	err := db.Where("wallet_id = ? AND type = ? AND amount = ? AND id <> ?", transaction.WalletID, transaction.Type, transaction.Amount, transaction.ID).
+		Where("transaction_date BETWEEN ? AND ?", transaction.TransactionDate.AddDate(0, 0, -days), transaction.TransactionDate.AddDate(0, 0, days)).
+		Order("transaction_date, id").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dismissed, err := dismissedPairs(db, []uint{transaction.ID})
+	if err != nil {
+		return nil, err
+	}
+	duplicates := []models.Transaction{}
+	for _, candidate := range candidates {
+		if !dismissed[duplicatePair(transaction.ID, candidate.ID)] && isLikelyDuplicate(transaction, candidate, days) {
+			duplicates = append(duplicates, candidate)
+		}
+	}
+	return duplicates, nil
+}
+
+// groupDuplicates mengelompokkan transaksi yang saling duplikat (union-find).
+// Transaksi harus sudah urut per dompet, tipe, nominal lalu tanggal.
+func groupDuplicates(transactions []models.Transaction, dismissed map[[2]uint]bool, days int) [][]models.Transaction {
+	parent := make([]int, len(transactions))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	window := time.Duration(days) * 24 * time.Hour
+	for i := range transactions {
+		for j := i + 1; j < len(transactions); j++ {
+			a, b := transactions[i], transactions[j]
+			if a.WalletID != b.WalletID || a.Type != b.Type || toCents(a.Amount) != toCents(b.Amount) ||
+				b.TransactionDate.Sub(a.TransactionDate) > window {
+				break
+			}
+			if !dismissed[duplicatePair(a.ID, b.ID)] && isLikelyDuplicate(a, b, days) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := map[int][]models.Transaction{}
+	var roots []int
+	for i, transaction := range transactions {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], transaction)
+	}
+	groups := [][]models.Transaction{}
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups
+}
+
+// GetDuplicateTransactions: Daftar kelompok transaksi yang diduga duplikat di
+// household aktif sejak ?since= (YYYY-MM-DD, bawaan 90 hari terakhir). Bisa
+// difilter dengan ?wallet_id= dan rentang hari ?days=.
+func GetDuplicateTransactions(c *gin.Context) {
+	var transactions []models.Transaction
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	days := duplicateWindowDays()
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 || parsed > 31 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 0 and 31"})
+			return
+		}
+		days = parsed
+	}
+
+	since := userToday(currentUser).AddDate(0, 0, -defaultDuplicateLookback)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be in YYYY-MM-DD format"})
+			return
+		}
+		since = parsed
+	}
+
+	scanned := func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("household_id = ? OR wallet_id IN (?)", getCurrentHousehold(c).ID, sharedWalletIDs(db, currentUser.ID)).
+			Where("transaction_date >= ?", since)
+		if walletID := c.Query("wallet_id"); walletID != "" {
+			tx = tx.Where("wallet_id = ?", walletID)
+		}
+		return tx
+	}
+	if err := db.Preload("Wallet").Preload("Category").Preload("Tags").Scopes(scanned).
+		Order("wallet_id, type, amount, transaction_date, id").Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load transactions"})
+		return
+	}
+
+	dismissed, err := dismissedPairs(db, db.Model(&models.Transaction{}).Select("id").Scopes(scanned))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load transactions"})
+		return
+	}
+
+	groups := groupDuplicates(transactions, dismissed, days)
+	// Kelompok terbaru ditampilkan lebih dulu
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i][0].TransactionDate.After(groups[j][0].TransactionDate)
+	})
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+}
+
+type DismissDuplicatesInput struct {
+	TransactionIDs []uint `json:"transaction_ids" binding:"required,min=2"`
+}
+
+// DismissDuplicates: Menandai sekelompok transaksi sebagai bukan duplikat
+func DismissDuplicates(c *gin.Context) {
+	var input DismissDuplicatesInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, id := range input.TransactionIDs {
+		if _, err := findTransactionWithRole(db, id, currentUser.ID, models.RoleEditor); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+			return
+		}
+	}
+
+	var rows []models.DuplicateDismissal
+	seen := map[[2]uint]bool{}
+	for i, a := range input.TransactionIDs {
+		for _, b := range input.TransactionIDs[i+1:] {
+			pair := duplicatePair(a, b)
+			if a == b || seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			rows = append(rows, models.DuplicateDismissal{TransactionID: pair[0], OtherTransactionID: pair[1], UserID: currentUser.ID})
+		}
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least two different transactions are required"})
+		return
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss duplicates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Duplicates dismissed"})
+}
+
+type MergeDuplicatesInput struct {
+	DuplicateIDs []uint `json:"duplicate_ids" binding:"required,min=1"`
+}
+
+// errDuplicateMismatch dikembalikan bila transaksi yang digabung berada di
+// dompet lain
+var errDuplicateMismatch = errors.New("duplicates must belong to the same wallet as the kept transaction")
+
+// MergeDuplicates: Mempertahankan transaksi :id dan menghapus duplikatnya.
+// Tag dan lampiran duplikat dipindahkan ke transaksi yang dipertahankan,
+// lalu jurnal duplikat dibatalkan sehingga saldo dompet terkoreksi.
+func MergeDuplicates(c *gin.Context) {
+	var input MergeDuplicatesInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	keep, ok := loadTransactionWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var duplicates []models.Transaction
+	for _, id := range input.DuplicateIDs {
+		if id == keep.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A transaction cannot be merged into itself"})
+			return
+		}
+		duplicate, err := findTransactionWithRole(db, id, currentUser.ID, models.RoleEditor)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+			return
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Tags").First(&keep, keep.ID).Error; err != nil {
+			return err
+		}
+		for _, duplicate := range duplicates {
+			if duplicate.WalletID != keep.WalletID {
+				return errDuplicateMismatch
+			}
+			if err := ensureNotReconciled(duplicate); err != nil {
+				return err
+			}
+
+			var tags []models.Tag
+			if err := tx.Model(&duplicate).Association("Tags").Find(&tags); err != nil {
+				return err
+			}
+			var missing []models.Tag
+			for _, tag := range tags {
+				if !transactionHasTag(keep, tag.ID) {
+					missing = append(missing, tag)
+					keep.Tags = append(keep.Tags, tag)
+				}
+			}
+			if len(missing) > 0 {
+				if err := tx.Model(&keep).Association("Tags").Append(missing); err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&models.Attachment{}).Where("transaction_id = ?", duplicate.ID).Update("transaction_id", keep.ID).Error; err != nil {
+				return err
+			}
+
+			if err := removeTransaction(tx, duplicate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errDuplicateMismatch) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errTransactionReconciled) || errors.Is(err, errExpenseHasRefunds) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge duplicates: " + err.Error()})
+		return
+	}
+
+	db.Preload("User").Preload("Wallet").Preload("Category").Preload("Tags").Preload("Splits.Category").First(&keep, keep.ID)
+	c.JSON(http.StatusOK, gin.H{"data": keep, "message": "Duplicates merged successfully"})
+}
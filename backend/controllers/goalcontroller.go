@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"dompet/backend/utils"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultGoalPaceMonths adalah jumlah bulan terakhir yang dipakai untuk
+// menghitung laju menabung bila ?pace_months= tidak diisi
+const defaultGoalPaceMonths = 3
+
+type GoalInput struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	TargetAmount float64 `json:"target_amount" binding:"required,gt=0"`
+	Currency     string  `json:"currency" binding:"omitempty,max=5"`
+	TargetDate   *string `json:"target_date"` // YYYY-MM-DD
+	WalletID     *uint   `json:"wallet_id"`
+	TagID        *uint   `json:"tag_id"`
+}
+
+// GoalContribution adalah satu setoran (atau penarikan bila negatif) ke
+// target tabungan
+type GoalContribution struct {
+	Date        time.Time `json:"date"`
+	Source      string    `json:"source"`    // "transfer" atau "transaction"
+	SourceID    uint      `json:"source_id"` // ID jurnal transfer atau ID transaksi
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+}
+
+// GoalProgress adalah target tabungan beserta progres dan proyeksinya
+type GoalProgress struct {
+	models.SavingsGoal
+	Saved      float64              `json:"saved"`
+	PaceMonths int                  `json:"pace_months"`
+	Projection utils.GoalProjection `json:"projection"`
+}
+
+// applyGoalInput memvalidasi input dan menyalinnya ke target tabungan. Dompet
+// dan tag harus berada di household target, dan mata uang target mengikuti
+// dompetnya.
+func applyGoalInput(db *gorm.DB, goal *models.SavingsGoal, input GoalInput, user models.User) error {
+	if input.WalletID == nil && input.TagID == nil {
+		return errors.New("a goal needs a wallet_id or a tag_id to track contributions")
+	}
+
+	currency := strings.ToUpper(input.Currency)
+	if input.WalletID != nil {
+		wallet, err := findWalletWithRole(db, *input.WalletID, user.ID, models.RoleViewer)
+		if err != nil || wallet.HouseholdID != goal.HouseholdID {
+			return errors.New("wallet not found in this household")
+		}
+		if wallet.IsLiability() {
+			return errors.New("credit card and loan wallets cannot hold a savings goal")
+		}
+		if currency != "" && currency != wallet.Currency {
+			return errors.New("currency must match the currency of the linked wallet")
+		}
+		currency = wallet.Currency
+	}
+	if input.TagID != nil {
+		var count int64
+		db.Model(&models.Tag{}).Scopes(householdScope(goal.HouseholdID)).Where("id = ?", *input.TagID).Count(&count)
+		if count == 0 {
+			return errors.New("tag not found in this household")
+		}
+	}
+	if currency == "" {
+		currency = user.Currency
+	}
+
+	goal.TargetDate = nil
+	if input.TargetDate != nil && *input.TargetDate != "" {
+		date, err := time.Parse("2006-01-02", *input.TargetDate)
+		if err != nil {
+			return errors.New("target_date must be in YYYY-MM-DD format")
+		}
+		goal.TargetDate = &date
+	}
+
+	goal.Name = strings.TrimSpace(input.Name)
+	goal.TargetAmount = input.TargetAmount
+	goal.Currency = currency
+	goal.WalletID = input.WalletID
+	goal.TagID = input.TagID
+	return nil
+}
+
+// goalContributions mengumpulkan setoran target tabungan: transfer masuk
+// (positif) dan keluar (negatif) dompet target, serta transaksi ber-tag target
+// dengan mata uang yang sama. Transaksi ber-tag selalu dihitung sebagai
+// setoran, baik pemasukan maupun pengeluaran (mis. "Nabung DP" yang dicatat
+// sebagai pengeluaran). Hasilnya diurutkan dari yang terbaru.
+func goalContributions(db *gorm.DB, goal models.SavingsGoal) ([]GoalContribution, error) {
+	contributions := []GoalContribution{}
+
+	if goal.WalletID != nil {
+		var transfers []GoalContribution
+		err := db.Table("postings").
+			Select("journal_entries.entry_date AS date, journal_entries.id AS source_id, journal_entries.description, SUM(postings.debit - postings.credit) AS amount").
+			Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+			Joins("JOIN accounts ON accounts.id = postings.account_id").
+			Where("accounts.wallet_id = ? AND journal_entries.kind = ? AND journal_entries.deleted_at IS NULL", *goal.WalletID, models.JournalTransfer).
+			Group("journal_entries.id, journal_entries.entry_date, journal_entries.description").
+			Scan(&transfers).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, transfer := range transfers {
+			transfer.Source = models.GoalSourceTransfer
+			contributions = append(contributions, transfer)
+		}
+	}
+
+	if goal.TagID != nil {
+		var transactions []GoalContribution
+		err := db.Table("transactions").
+			Select("transactions.transaction_date AS date, transactions.id AS source_id, transactions.description, transactions.amount").
+			Joins("JOIN transaction_tags ON transaction_tags.transaction_id = transactions.id").
+			Joins("JOIN wallets ON wallets.id = transactions.wallet_id").
+			Where("transaction_tags.tag_id = ? AND wallets.currency = ? AND transactions.deleted_at IS NULL", *goal.TagID, goal.Currency).
+			Scan(&transactions).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, transaction := range transactions {
+			transaction.Source = models.GoalSourceTransaction
+			contributions = append(contributions, transaction)
+		}
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		if !contributions[i].Date.Equal(contributions[j].Date) {
+			return contributions[i].Date.After(contributions[j].Date)
+		}
+		return contributions[i].SourceID > contributions[j].SourceID
+	})
+	return contributions, nil
+}
+
+// goalProgress menghitung total setoran dan proyeksi target tabungan. Laju
+// menabung adalah rata-rata setoran selama paceMonths bulan terakhir.
+func goalProgress(db *gorm.DB, goal models.SavingsGoal, paceMonths int, today time.Time) (GoalProgress, error) {
+	contributions, err := goalContributions(db, goal)
+	if err != nil {
+		return GoalProgress{}, err
+	}
+
+	// Batas atas eksklusif besok, agar setoran hari ini ikut terhitung
+	// berapa pun jam yang tersimpan bersama tanggalnya
+	since := today.AddDate(0, -paceMonths, 0)
+	tomorrow := today.AddDate(0, 0, 1)
+	var saved, recent float64
+	for _, contribution := range contributions {
+		saved += contribution.Amount
+		if contribution.Date.After(since) && contribution.Date.Before(tomorrow) {
+			recent += contribution.Amount
+		}
+	}
+	saved = float64(toCents(saved)) / 100
+
+	return GoalProgress{
+		SavingsGoal: goal,
+		Saved:       saved,
+		PaceMonths:  paceMonths,
+		Projection:  utils.ProjectGoal(goal.TargetAmount, saved, recent, paceMonths, goal.TargetDate, today),
+	}, nil
+}
+
+// goalPaceMonths membaca ?pace_months= dan menulis respons 400 bila tidak valid
+func goalPaceMonths(c *gin.Context) (int, bool) {
+	raw := c.Query("pace_months")
+	if raw == "" {
+		return defaultGoalPaceMonths, true
+	}
+	months, err := strconv.Atoi(raw)
+	if err != nil || months < 1 || months > 24 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pace_months must be between 1 and 24"})
+		return 0, false
+	}
+	return months, true
+}
+
+// loadGoal mencari target tabungan di household aktif berdasarkan parameter URL
+func loadGoal(c *gin.Context, db *gorm.DB) (models.SavingsGoal, bool) {
+	var goal models.SavingsGoal
+	if err := db.Scopes(tenantScope(c)).Preload("Wallet").Preload("Tag").Where("id = ?", c.Param("id")).First(&goal).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return goal, false
+	}
+	return goal, true
+}
+
+// GetAllGoals: Mendapatkan semua target tabungan household aktif beserta progresnya
+func GetAllGoals(c *gin.Context) {
+	var goals []models.SavingsGoal
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	paceMonths, ok := goalPaceMonths(c)
+	if !ok {
+		return
+	}
+
+	db.Scopes(tenantScope(c)).Preload("Wallet").Preload("Tag").Order("target_date IS NULL, target_date, id").Find(&goals)
+
+	today := userToday(currentUser)
+	progress := make([]GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		row, err := goalProgress(db, goal, paceMonths, today)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate goal progress"})
+			return
+		}
+		progress = append(progress, row)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// CreateGoal: Membuat target tabungan baru
+func CreateGoal(c *gin.Context) {
+	var input GoalInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal := models.SavingsGoal{UserID: currentUser.ID, HouseholdID: getCurrentHousehold(c).ID}
+	if err := applyGoalInput(db, &goal, input, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Create(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
+		return
+	}
+
+	db.Preload("Wallet").Preload("Tag").First(&goal, goal.ID)
+	c.JSON(http.StatusOK, gin.H{"data": goal})
+}
+
+// GetGoalByID: Mendapatkan satu target tabungan
+func GetGoalByID(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	goal, ok := loadGoal(c, db)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": goal})
+}
+
+// UpdateGoal: Mengubah target tabungan
+func UpdateGoal(c *gin.Context) {
+	var input GoalInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	goal, ok := loadGoal(c, db)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := applyGoalInput(db, &goal, input, currentUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Model(&goal).Select("name", "target_amount", "currency", "target_date", "wallet_id", "tag_id").Updates(&goal).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return
+	}
+
+	db.Preload("Wallet").Preload("Tag").First(&goal, goal.ID)
+	c.JSON(http.StatusOK, gin.H{"data": goal})
+}
+
+// DeleteGoal: Menghapus target tabungan. Transfer dan transaksinya tidak ikut terhapus.
+func DeleteGoal(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	goal, ok := loadGoal(c, db)
+	if !ok {
+		return
+	}
+
+	if err := db.Delete(&goal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Goal deleted successfully"})
+}
+
+// GetGoalProgress: Persentase tercapai, setoran bulanan yang dibutuhkan untuk
+// mencapai tanggal target, dan perkiraan tanggal tercapai berdasarkan rata-rata
+// setoran ?pace_months= bulan terakhir (default 3)
+func GetGoalProgress(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	goal, ok := loadGoal(c, db)
+	if !ok {
+		return
+	}
+	paceMonths, ok := goalPaceMonths(c)
+	if !ok {
+		return
+	}
+
+	progress, err := goalProgress(db, goal, paceMonths, userToday(currentUser))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate goal progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// GetGoalContributions: Daftar setoran dan penarikan target tabungan
+func GetGoalContributions(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	goal, ok := loadGoal(c, db)
+	if !ok {
+		return
+	}
+
+	contributions, err := goalContributions(db, goal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load goal contributions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": contributions})
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type HouseholdInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// loadHouseholdWithRole mencari household dari parameter URL dan memastikan
+// user punya minimal peran minRole di dalamnya
+func loadHouseholdWithRole(c *gin.Context, db *gorm.DB, minRole string) (models.Household, bool) {
+	var household models.Household
+	var member models.HouseholdMember
+	currentUser, _ := getCurrentUser(c)
+
+	if err := db.Where("household_id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return household, false
+	}
+	if roleRank[member.Role] < roleRank[minRole] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage this household"})
+		return household, false
+	}
+	if err := db.First(&household, member.HouseholdID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return household, false
+	}
+	household.Role = member.Role
+	return household, true
+}
+
+// GetMyHouseholds: Mendapatkan semua household yang diikuti user
+func GetMyHouseholds(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	var members []models.HouseholdMember
+	db.Preload("Household").Where("user_id = ?", currentUser.ID).Order("household_id").Find(&members)
+
+	households := make([]models.Household, 0, len(members))
+	for _, member := range members {
+		household := member.Household
+		household.Role = member.Role
+		households = append(households, household)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": households, "current_household_id": getCurrentHousehold(c).ID})
+}
+
+// CreateHousehold: Membuat household baru dengan user sebagai owner
+func CreateHousehold(c *gin.Context) {
+	var input HouseholdInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household := models.Household{Name: input.Name, CreatedByID: currentUser.ID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&household).Error; err != nil {
+			return err
+		}
+		owner := models.HouseholdMember{HouseholdID: household.ID, UserID: currentUser.ID, Role: models.RoleOwner}
+		return tx.Create(&owner).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create household"})
+		return
+	}
+	household.Role = models.RoleOwner
+
+	c.JSON(http.StatusOK, gin.H{"data": household})
+}
+
+// UpdateHousehold: Mengganti nama household (hanya owner)
+func UpdateHousehold(c *gin.Context) {
+	var input HouseholdInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	household, ok := loadHouseholdWithRole(c, db, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db.Model(&household).Update("name", input.Name)
+	c.JSON(http.StatusOK, gin.H{"data": household})
+}
+
+// GetHouseholdMembers: Mendapatkan daftar anggota household
+func GetHouseholdMembers(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	household, ok := loadHouseholdWithRole(c, db, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var members []models.HouseholdMember
+	db.Preload("User").Where("household_id = ?", household.ID).Order("created_at").Find(&members)
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+type HouseholdMemberInput struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// AddHouseholdMember: Menambahkan user terdaftar ke household (hanya owner)
+func AddHouseholdMember(c *gin.Context) {
+	var input HouseholdMemberInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	household, ok := loadHouseholdWithRole(c, db, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := db.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(input.Email))).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No registered user with this email"})
+		return
+	}
+
+	var existing models.HouseholdMember
+	if err := db.Where("household_id = ? AND user_id = ?", household.ID, user.ID).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this household"})
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	member := models.HouseholdMember{HouseholdID: household.ID, UserID: user.ID, Role: input.Role}
+	if err := db.Create(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+	member.User = user
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+type HouseholdRoleInput struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// UpdateHouseholdMember: Mengubah peran anggota household (hanya owner)
+func UpdateHouseholdMember(c *gin.Context) {
+	var input HouseholdRoleInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	household, ok := loadHouseholdWithRole(c, db, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var member models.HouseholdMember
+	if err := db.Where("household_id = ? AND user_id = ?", household.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if member.Role == models.RoleOwner && input.Role != models.RoleOwner && isLastHouseholdOwner(db, member) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A household must keep at least one owner"})
+		return
+	}
+
+	db.Model(&member).Update("role", input.Role)
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveHouseholdMember: Mengeluarkan anggota. Owner bisa mengeluarkan siapa
+// saja, anggota lain hanya bisa keluar sendiri.
+func RemoveHouseholdMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	household, ok := loadHouseholdWithRole(c, db, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var member models.HouseholdMember
+	if err := db.Where("household_id = ? AND user_id = ?", household.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if household.Role != models.RoleOwner && member.UserID != currentUser.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only an owner can remove other members"})
+		return
+	}
+	if member.Role == models.RoleOwner && isLastHouseholdOwner(db, member) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A household must keep at least one owner"})
+		return
+	}
+	if household.Personal && household.CreatedByID == member.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The creator cannot leave their personal household"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := transferHouseholdWallets(tx, member, currentUser.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&member).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Member removed successfully"})
+}
+
+// transferHouseholdWallets memindahkan kepemilikan dompet household yang
+// dibuat anggota yang keluar ke owner household, sehingga anggota tersebut
+// tidak lagi memegang hak owner atas dompet itu. Undangan dompet bersama
+// yang diterimanya (editor/viewer) tetap berlaku.
+func transferHouseholdWallets(tx *gorm.DB, member models.HouseholdMember, removedByID uint) error {
+	// Owner yang mengeluarkan anggota didahulukan
+	var owners []models.HouseholdMember
+	if err := tx.Where("household_id = ? AND role = ? AND user_id <> ?", member.HouseholdID, models.RoleOwner, member.UserID).
+		Order("id").Find(&owners).Error; err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	newOwnerID := owners[0].UserID
+	for _, owner := range owners {
+		if owner.UserID == removedByID {
+			newOwnerID = owner.UserID
+		}
+	}
+
+	walletIDs := tx.Model(&models.Wallet{}).Unscoped().Select("id").Where("household_id = ?", member.HouseholdID)
+	if err := tx.Where("user_id = ? AND role = ? AND wallet_id IN (?)", member.UserID, models.RoleOwner, walletIDs).
+		Delete(&models.WalletMember{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(&models.Wallet{}).Where("household_id = ? AND user_id = ?", member.HouseholdID, member.UserID).
+		Updates(map[string]interface{}{"user_id": newOwnerID, "version": gorm.Expr("version + 1")}).Error
+}
+
+// isLastHouseholdOwner bernilai true bila member adalah satu-satunya owner
+func isLastHouseholdOwner(db *gorm.DB, member models.HouseholdMember) bool {
+	var owners int64
+	db.Model(&models.HouseholdMember{}).Where("household_id = ? AND role = ?", member.HouseholdID, models.RoleOwner).Count(&owners)
+	return owners <= 1
+}
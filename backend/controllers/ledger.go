@@ -0,0 +1,455 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errUnbalancedJournal   = errors.New("journal entry is not balanced")
+	errCreditLimitExceeded = errors.New("credit limit exceeded")
+	errBalanceCapExceeded  = errors.New("e-wallet balance cap exceeded")
+	errWalletArchived      = errors.New("wallet is archived")
+	errCategoryArchived    = errors.New("category is archived")
+)
+
+// isWalletRuleError bernilai true bila jurnal ditolak karena aturan dompet
+// (batas saldo, dompet atau kategori sudah diarsipkan), bukan karena
+// kesalahan server
+func isWalletRuleError(err error) bool {
+	return errors.Is(err, errCreditLimitExceeded) || errors.Is(err, errBalanceCapExceeded) ||
+		errors.Is(err, errWalletArchived) || errors.Is(err, errCategoryArchived)
+}
+
+// ensureWalletActive menolak pencatatan baru pada dompet yang diarsipkan
+func ensureWalletActive(wallet models.Wallet) error {
+	if wallet.ArchivedAt != nil {
+		return errWalletArchived
+	}
+	return nil
+}
+
+// ensureCategoryActive menolak pencatatan baru pada kategori yang diarsipkan
+func ensureCategoryActive(category models.Category) error {
+	if category.ArchivedAt != nil {
+		return errCategoryArchived
+	}
+	return nil
+}
+
+// toCents membulatkan nominal ke satuan sen agar perbandingan float aman
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// walletAccount mengembalikan akun buku besar milik dompet. Akun dibuat
+// bersama dompetnya (createWalletAccount), dan untuk dompet lama oleh migrasi
+// 0008_household_backfill, sehingga pembacaan tidak pernah menulis ke database.
+func walletAccount(tx *gorm.DB, wallet models.Wallet) (models.Account, error) {
+	var account models.Account
+	err := tx.Where("wallet_id = ?", wallet.ID).First(&account).Error
+	return account, err
+}
+
+// createWalletAccount membuat akun buku besar untuk dompet baru
+func createWalletAccount(tx *gorm.DB, wallet models.Wallet) (models.Account, error) {
+	accountType := models.AccountAsset
+	if wallet.IsLiability() {
+		accountType = models.AccountLiability
+	}
+	account := models.Account{
+		UserID:      wallet.UserID,
+		HouseholdID: wallet.HouseholdID,
+		Name:        wallet.Name,
+		Type:        accountType,
+		WalletID:    &wallet.ID,
+	}
+	return account, tx.Create(&account).Error
+}
+
+// categoryAccount mengembalikan akun pendapatan/beban milik kategori
+func categoryAccount(tx *gorm.DB, category models.Category) (models.Account, error) {
+	var account models.Account
+	err := tx.Where("category_id = ?", category.ID).First(&account).Error
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return account, err
+	}
+
+	accountType := models.AccountExpense
+	if category.Type == "income" {
+		accountType = models.AccountIncome
+	}
+	account = models.Account{
+		UserID:      category.UserID,
+		HouseholdID: category.HouseholdID,
+		Name:        category.Name,
+		Type:        accountType,
+		CategoryID:  &category.ID,
+	}
+	return account, tx.Create(&account).Error
+}
+
+// systemAccount mengembalikan akun sistem household berdasarkan kodenya
+func systemAccount(tx *gorm.DB, householdID, userID uint, code, name, accountType string) (models.Account, error) {
+	account := models.Account{HouseholdID: householdID, UserID: userID, Code: code, Name: name, Type: accountType}
+	err := tx.Scopes(householdScope(householdID)).Where("code = ?", code).FirstOrCreate(&account).Error
+	return account, err
+}
+
+// openingBalanceAccount adalah akun ekuitas penampung saldo awal dompet
+func openingBalanceAccount(tx *gorm.DB, wallet models.Wallet) (models.Account, error) {
+	return systemAccount(tx, wallet.HouseholdID, wallet.UserID, "opening_balance", "Saldo Awal", models.AccountEquity)
+}
+
+// postOpeningBalance membukukan saldo awal dompet baru
+func postOpeningBalance(tx *gorm.DB, wallet models.Wallet, amount float64) error {
+	if toCents(amount) == 0 {
+		return nil
+	}
+	account, err := walletAccount(tx, wallet)
+	if err != nil {
+		return err
+	}
+	equity, err := openingBalanceAccount(tx, wallet)
+	if err != nil {
+		return err
+	}
+	entry := models.JournalEntry{
+		UserID:      wallet.UserID,
+		HouseholdID: wallet.HouseholdID,
+		Kind:        models.JournalOpening,
+		Description: "Saldo awal " + wallet.Name,
+		EntryDate:   time.Now(),
+		Postings:    movePostings(account.ID, equity.ID, amount),
+	}
+	return postJournal(tx, &entry)
+}
+
+// movePostings membuat sepasang posting yang memindahkan amount ke akun
+// debitAccountID dari akun creditAccountID. Nominal negatif membalik arahnya.
+func movePostings(debitAccountID, creditAccountID uint, amount float64) []models.Posting {
+	if amount < 0 {
+		debitAccountID, creditAccountID = creditAccountID, debitAccountID
+		amount = -amount
+	}
+	return []models.Posting{
+		{AccountID: debitAccountID, Debit: amount},
+		{AccountID: creditAccountID, Credit: amount},
+	}
+}
+
+// combinePostings menggabungkan posting ke akun yang sama menjadi satu baris,
+// sehingga dompet transaksi yang dipecah tetap didebit/dikredit sekali
+func combinePostings(postings []models.Posting) []models.Posting {
+	index := map[uint]int{}
+	combined := make([]models.Posting, 0, len(postings))
+	for _, posting := range postings {
+		if i, ok := index[posting.AccountID]; ok {
+			combined[i].Debit += posting.Debit
+			combined[i].Credit += posting.Credit
+			continue
+		}
+		index[posting.AccountID] = len(combined)
+		combined = append(combined, posting)
+	}
+	return combined
+}
+
+// validateJournal memastikan setiap posting hanya berisi debit atau kredit
+// dan total keduanya seimbang
+func validateJournal(entry models.JournalEntry) error {
+	if len(entry.Postings) < 2 {
+		return errUnbalancedJournal
+	}
+	var debit, credit int64
+	for _, posting := range entry.Postings {
+		d, c := toCents(posting.Debit), toCents(posting.Credit)
+		if d < 0 || c < 0 || (d == 0) == (c == 0) {
+			return errUnbalancedJournal
+		}
+		debit += d
+		credit += c
+	}
+	if debit != credit {
+		return errUnbalancedJournal
+	}
+	return nil
+}
+
+// applyPostings menerapkan efek posting ke saldo dompet yang terhubung.
+// sign bernilai 1 untuk membukukan dan -1 untuk membatalkan. Saat membukukan,
+// limit kartu kredit dan batas saldo e-wallet ikut diperiksa.
+func applyPostings(tx *gorm.DB, postings []models.Posting, sign float64) error {
+	accountIDs := make([]uint, 0, len(postings))
+	for _, posting := range postings {
+		accountIDs = append(accountIDs, posting.AccountID)
+	}
+	var accounts []models.Account
+	if err := tx.Where("id IN ? AND wallet_id IS NOT NULL", accountIDs).Find(&accounts).Error; err != nil {
+		return err
+	}
+	walletOf := map[uint]uint{}
+	for _, account := range accounts {
+		walletOf[account.ID] = *account.WalletID
+	}
+
+	deltas := map[uint]float64{}
+	walletIDs := []uint{}
+	for _, posting := range postings {
+		walletID, ok := walletOf[posting.AccountID]
+		if !ok {
+			continue
+		}
+		if _, seen := deltas[walletID]; !seen {
+			walletIDs = append(walletIDs, walletID)
+		}
+		deltas[walletID] += sign * (posting.Debit - posting.Credit)
+	}
+	if len(walletIDs) == 0 {
+		return nil
+	}
+
+	// Kunci semua dompet sekaligus dalam urutan id agar pencatatan bersamaan
+	// tidak lolos dari pemeriksaan limit dengan saldo yang sudah basi, dan
+	// transfer dua arah yang berjalan bersamaan tidak saling deadlock
+	var wallets []models.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", walletIDs).Order("id").Find(&wallets).Error; err != nil {
+		return err
+	}
+	if len(wallets) != len(walletIDs) {
+		return gorm.ErrRecordNotFound
+	}
+	for _, wallet := range wallets {
+		delta := deltas[wallet.ID]
+		if err := tx.Model(&models.Wallet{}).Where("id = ?", wallet.ID).
+			Update("balance", gorm.Expr("balance + ?", delta)).Error; err != nil {
+			return err
+		}
+		if sign < 0 {
+			continue
+		}
+
+		wallet.Balance += delta
+		if err := checkWalletLimits(wallet, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWalletLimits menolak perubahan saldo yang melewati limit kartu kredit
+// atau batas saldo e-wallet
+func checkWalletLimits(wallet models.Wallet, delta float64) error {
+	switch {
+	case delta < 0 && wallet.Type == models.WalletCreditCard && wallet.CreditLimit != nil:
+		if toCents(-wallet.Balance) > toCents(*wallet.CreditLimit) {
+			return errCreditLimitExceeded
+		}
+	case delta > 0 && wallet.Type == models.WalletEWallet && wallet.BalanceCap != nil:
+		if toCents(wallet.Balance) > toCents(*wallet.BalanceCap) {
+			return errBalanceCapExceeded
+		}
+	}
+	return nil
+}
+
+// postJournal menyimpan jurnal yang seimbang lalu memperbarui saldo dompet
+func postJournal(tx *gorm.DB, entry *models.JournalEntry) error {
+	if err := validateJournal(*entry); err != nil {
+		return err
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+	return applyPostings(tx, entry.Postings, 1)
+}
+
+// deleteJournal membatalkan efek jurnal ke saldo dompet lalu menghapusnya
+func deleteJournal(tx *gorm.DB, entry models.JournalEntry) error {
+	var postings []models.Posting
+	if err := tx.Where("journal_entry_id = ?", entry.ID).Find(&postings).Error; err != nil {
+		return err
+	}
+	if err := applyPostings(tx, postings, -1); err != nil {
+		return err
+	}
+	if err := tx.Where("journal_entry_id = ?", entry.ID).Delete(&models.Posting{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&entry).Error
+}
+
+// voidedPosting adalah salinan posting pada jurnal yang dibatalkan
+type voidedPosting struct {
+	AccountID uint    `json:"account_id"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
+}
+
+// voidJournal seperti deleteJournal, tetapi jurnalnya hanya masuk tempat
+// sampah dengan salinan posting di VoidedPostings sehingga bisa dibukukan
+// lagi dengan restoreJournal
+func voidJournal(tx *gorm.DB, entry models.JournalEntry) error {
+	var postings []models.Posting
+	if err := tx.Where("journal_entry_id = ?", entry.ID).Find(&postings).Error; err != nil {
+		return err
+	}
+	if err := applyPostings(tx, postings, -1); err != nil {
+		return err
+	}
+
+	snapshot := make([]voidedPosting, len(postings))
+	for i, posting := range postings {
+		snapshot[i] = voidedPosting{AccountID: posting.AccountID, Debit: posting.Debit, Credit: posting.Credit}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("journal_entry_id = ?", entry.ID).Delete(&models.Posting{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&entry).Update("voided_postings", data).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&entry).Error
+}
+
+// voidedAccounts mengembalikan ID akun yang tersentuh jurnal yang dibatalkan
+func voidedAccounts(entry models.JournalEntry) ([]uint, error) {
+	var snapshot []voidedPosting
+	if err := json.Unmarshal(entry.VoidedPostings, &snapshot); err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(snapshot))
+	for i, posting := range snapshot {
+		ids[i] = posting.AccountID
+	}
+	return ids, nil
+}
+
+// restoreJournal membukukan kembali jurnal yang dibatalkan voidJournal
+func restoreJournal(tx *gorm.DB, entry models.JournalEntry) error {
+	var snapshot []voidedPosting
+	if err := json.Unmarshal(entry.VoidedPostings, &snapshot); err != nil {
+		return err
+	}
+	postings := make([]models.Posting, len(snapshot))
+	for i, posting := range snapshot {
+		postings[i] = models.Posting{JournalEntryID: entry.ID, AccountID: posting.AccountID, Debit: posting.Debit, Credit: posting.Credit}
+	}
+
+	if err := tx.Unscoped().Model(&entry).Updates(map[string]interface{}{"deleted_at": nil, "voided_postings": nil}).Error; err != nil {
+		return err
+	}
+	if len(postings) > 0 {
+		if err := tx.Create(&postings).Error; err != nil {
+			return err
+		}
+	}
+	return applyPostings(tx, postings, 1)
+}
+
+// transactionJournal menyusun jurnal untuk sebuah transaksi: pemasukan dan
+// refund mendebit dompet dan mengkredit akun kategori, pengeluaran sebaliknya
+func transactionJournal(tx *gorm.DB, transaction models.Transaction) (models.JournalEntry, error) {
+	var wallet models.Wallet
+	if err := tx.First(&wallet, transaction.WalletID).Error; err != nil {
+		return models.JournalEntry{}, err
+	}
+	walletAcc, err := walletAccount(tx, wallet)
+	if err != nil {
+		return models.JournalEntry{}, err
+	}
+
+	// Transaksi yang dipecah dibukukan per baris rincian; tanpa rincian,
+	// seluruh nominal masuk ke kategori transaksi
+	var splits []models.TransactionSplit
+	if err := tx.Where("transaction_id = ?", transaction.ID).Order("id").Find(&splits).Error; err != nil {
+		return models.JournalEntry{}, err
+	}
+	if len(splits) == 0 {
+		splits = []models.TransactionSplit{{CategoryID: transaction.CategoryID, Amount: transaction.Amount}}
+	}
+
+	var postings []models.Posting
+	for _, split := range splits {
+		var category models.Category
+		if err := tx.First(&category, split.CategoryID).Error; err != nil {
+			return models.JournalEntry{}, err
+		}
+		categoryAcc, err := categoryAccount(tx, category)
+		if err != nil {
+			return models.JournalEntry{}, err
+		}
+
+		// Pemasukan dan refund menambah dompet, pengeluaran menguranginya.
+		// Refund mengkredit akun beban kategorinya sehingga bebannya berkurang.
+		amount := split.Amount
+		if transaction.Type == "expense" {
+			amount = -amount
+		}
+		postings = append(postings, movePostings(walletAcc.ID, categoryAcc.ID, amount)...)
+	}
+
+	return models.JournalEntry{
+		UserID:        transaction.UserID,
+		HouseholdID:   transaction.HouseholdID,
+		TransactionID: &transaction.ID,
+		Kind:          models.JournalTransaction,
+		Description:   transaction.Description,
+		EntryDate:     transaction.TransactionDate,
+		Postings:      combinePostings(postings),
+	}, nil
+}
+
+// postTransaction membukukan transaksi ke buku besar
+func postTransaction(tx *gorm.DB, transaction models.Transaction) error {
+	entry, err := transactionJournal(tx, transaction)
+	if err != nil {
+		return err
+	}
+	return postJournal(tx, &entry)
+}
+
+// unpostTransaction membatalkan pembukuan transaksi. Transaksi lama yang
+// dibuat sebelum buku besar ada tidak punya jurnal, sehingga efeknya dibatalkan
+// dengan jurnal penyesuaian.
+func unpostTransaction(tx *gorm.DB, transaction models.Transaction) error {
+	var entries []models.JournalEntry
+	if err := tx.Where("transaction_id = ?", transaction.ID).Find(&entries).Error; err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := deleteJournal(tx, entry); err != nil {
+			return err
+		}
+	}
+	if len(entries) > 0 {
+		return nil
+	}
+
+	reversal, err := transactionJournal(tx, transaction)
+	if err != nil {
+		return err
+	}
+	for i := range reversal.Postings {
+		p := &reversal.Postings[i]
+		p.Debit, p.Credit = p.Credit, p.Debit
+	}
+	reversal.TransactionID = nil
+	reversal.Kind = models.JournalAdjustment
+	reversal.Description = "Pembatalan: " + transaction.Description
+	reversal.EntryDate = time.Now()
+	return postJournal(tx, &reversal)
+}
@@ -0,0 +1,393 @@
+package controllers
+
+import (
+	"dompet/backend/database/dbtest"
+	"dompet/backend/models"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ledgerFixture adalah satu user dengan household pribadinya di database uji
+type ledgerFixture struct {
+	db        *gorm.DB
+	user      models.User
+	household models.Household
+}
+
+func newLedgerFixture(t *testing.T) *ledgerFixture {
+	t.Helper()
+	db := dbtest.Open(t)
+	f := &ledgerFixture{db: db}
+	f.user = models.User{Name: "Ani", Email: "ani@example.com", PasswordHash: "-", Currency: "IDR", Timezone: "Asia/Jakarta"}
+	f.mustCreate(t, &f.user)
+	f.household = models.Household{Name: "Pribadi", CreatedByID: f.user.ID, Personal: true}
+	f.mustCreate(t, &f.household)
+	f.mustCreate(t, &models.HouseholdMember{HouseholdID: f.household.ID, UserID: f.user.ID, Role: models.RoleOwner})
+	f.household.Role = models.RoleOwner
+	return f
+}
+
+func (f *ledgerFixture) mustCreate(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := f.db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+// wallet membuat dompet beserta akun buku besar dan saldo awalnya seperti
+// CreateWallet
+func (f *ledgerFixture) wallet(t *testing.T, wallet models.Wallet, opening float64) models.Wallet {
+	t.Helper()
+	wallet.UserID, wallet.HouseholdID = f.user.ID, f.household.ID
+	if wallet.Type == "" {
+		wallet.Type = models.WalletCash
+	}
+	if wallet.Currency == "" {
+		wallet.Currency = "IDR"
+	}
+	err := f.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&wallet).Error; err != nil {
+			return err
+		}
+		if _, err := createWalletAccount(tx, wallet); err != nil {
+			return err
+		}
+		return postOpeningBalance(tx, wallet, opening)
+	})
+	if err != nil {
+		t.Fatalf("create wallet %q: %v", wallet.Name, err)
+	}
+	return wallet
+}
+
+func (f *ledgerFixture) category(t *testing.T, name, categoryType string) models.Category {
+	t.Helper()
+	category := models.Category{UserID: f.user.ID, HouseholdID: f.household.ID, Name: name, Type: categoryType}
+	f.mustCreate(t, &category)
+	return category
+}
+
+// transaction mencatat dan membukukan transaksi
+func (f *ledgerFixture) transaction(t *testing.T, wallet models.Wallet, category models.Category, amount float64) models.Transaction {
+	t.Helper()
+	transaction := models.Transaction{
+		UserID:          f.user.ID,
+		HouseholdID:     f.household.ID,
+		WalletID:        wallet.ID,
+		CategoryID:      category.ID,
+		Amount:          amount,
+		Type:            category.Type,
+		Description:     category.Name,
+		TransactionDate: time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Status:          models.TransactionCleared,
+	}
+	err := f.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+		return postTransaction(tx, transaction)
+	})
+	if err != nil {
+		t.Fatalf("post transaction: %v", err)
+	}
+	return transaction
+}
+
+// transfer membukukan jurnal transfer antar dompet. Jurnal dikembalikan
+// tanpa Postings, seperti hasil walletJournalEntries.
+func (f *ledgerFixture) transfer(t *testing.T, from, to models.Wallet, amount float64) models.JournalEntry {
+	t.Helper()
+	entry := models.JournalEntry{
+		UserID:      f.user.ID,
+		HouseholdID: f.household.ID,
+		Kind:        models.JournalTransfer,
+		EntryDate:   time.Date(2026, time.March, 11, 0, 0, 0, 0, time.UTC),
+		Postings:    movePostings(f.account(t, to).ID, f.account(t, from).ID, amount),
+	}
+	if err := postJournal(f.db, &entry); err != nil {
+		t.Fatalf("post transfer: %v", err)
+	}
+	entry.Postings = nil
+	return entry
+}
+
+func (f *ledgerFixture) account(t *testing.T, wallet models.Wallet) models.Account {
+	t.Helper()
+	account, err := walletAccount(f.db, wallet)
+	if err != nil {
+		t.Fatalf("wallet account: %v", err)
+	}
+	return account
+}
+
+// balance membaca saldo dompet dari kolom balance, lalu memastikan sama
+// dengan jumlah posting akunnya di buku besar
+func (f *ledgerFixture) balance(t *testing.T, wallet models.Wallet) float64 {
+	t.Helper()
+	var stored models.Wallet
+	if err := f.db.Unscoped().First(&stored, wallet.ID).Error; err != nil {
+		t.Fatalf("load wallet: %v", err)
+	}
+	var ledger struct{ Total float64 }
+	f.db.Model(&models.Posting{}).Select("COALESCE(SUM(debit - credit), 0) AS total").
+		Where("account_id = ?", f.account(t, wallet).ID).Scan(&ledger)
+	if toCents(stored.Balance) != toCents(ledger.Total) {
+		t.Errorf("wallet %q balance = %.2f, ledger says %.2f", stored.Name, stored.Balance, ledger.Total)
+	}
+	return stored.Balance
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestValidateJournal(t *testing.T) {
+	posting := func(debit, credit float64) models.Posting {
+		return models.Posting{AccountID: 1, Debit: debit, Credit: credit}
+	}
+
+	tests := []struct {
+		name     string
+		postings []models.Posting
+		wantErr  bool
+	}{
+		{name: "seimbang", postings: []models.Posting{posting(100, 0), posting(0, 100)}},
+		{name: "banyak baris seimbang", postings: []models.Posting{posting(70, 0), posting(30, 0), posting(0, 100)}},
+		{name: "selisih pembulatan float", postings: []models.Posting{posting(0.1, 0), posting(0.2, 0), posting(0, 0.3)}},
+		{name: "tanpa posting", wantErr: true},
+		{name: "hanya satu posting", postings: []models.Posting{posting(100, 0)}, wantErr: true},
+		{name: "tidak seimbang", postings: []models.Posting{posting(100, 0), posting(0, 99.99)}, wantErr: true},
+		{name: "debit dan kredit di baris yang sama", postings: []models.Posting{posting(100, 100), posting(0, 0)}, wantErr: true},
+		{name: "baris nol", postings: []models.Posting{posting(100, 0), posting(0, 100), posting(0, 0)}, wantErr: true},
+		{name: "nominal negatif", postings: []models.Posting{posting(-100, 0), posting(0, -100)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJournal(models.JournalEntry{Postings: tt.postings})
+			if tt.wantErr && !errors.Is(err, errUnbalancedJournal) {
+				t.Errorf("validateJournal() error = %v, want errUnbalancedJournal", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("validateJournal() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestPostJournal(t *testing.T) {
+	tests := []struct {
+		name        string
+		wallet      models.Wallet
+		opening     float64
+		amount      float64 // positif menambah saldo dompet
+		wantErr     error
+		wantBalance float64
+	}{
+		{name: "pemasukan ke dompet tunai", opening: 1000, amount: 250, wantBalance: 1250},
+		{name: "pengeluaran boleh membuat saldo tunai negatif", opening: 100, amount: -300, wantBalance: -200},
+		{
+			name:        "pemakaian kartu kredit dalam limit",
+			wallet:      models.Wallet{Type: models.WalletCreditCard, CreditLimit: floatPtr(1000)},
+			amount:      -1000,
+			wantBalance: -1000,
+		},
+		{
+			name:        "pemakaian kartu kredit melewati limit",
+			wallet:      models.Wallet{Type: models.WalletCreditCard, CreditLimit: floatPtr(1000)},
+			opening:     -900,
+			amount:      -100.01,
+			wantErr:     errCreditLimitExceeded,
+			wantBalance: -900,
+		},
+		{
+			name:        "pembayaran kartu kredit tidak diperiksa limit",
+			wallet:      models.Wallet{Type: models.WalletCreditCard, CreditLimit: floatPtr(1000)},
+			opening:     -900,
+			amount:      500,
+			wantBalance: -400,
+		},
+		{
+			name:        "isi ulang e-wallet melewati batas saldo",
+			wallet:      models.Wallet{Type: models.WalletEWallet, BalanceCap: floatPtr(2000)},
+			opening:     1500,
+			amount:      600,
+			wantErr:     errBalanceCapExceeded,
+			wantBalance: 1500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newLedgerFixture(t)
+			tt.wallet.Name = "Dompet"
+			wallet := f.wallet(t, tt.wallet, tt.opening)
+			income := f.category(t, "Lain-lain", "income")
+			categoryAcc, err := categoryAccount(f.db, income)
+			if err != nil {
+				t.Fatalf("categoryAccount() error = %v", err)
+			}
+
+			entry := models.JournalEntry{
+				UserID:      f.user.ID,
+				HouseholdID: f.household.ID,
+				Kind:        models.JournalAdjustment,
+				EntryDate:   time.Now(),
+				Postings:    movePostings(f.account(t, wallet).ID, categoryAcc.ID, tt.amount),
+			}
+			// Pemanggil menjalankan postJournal di dalam database transaction
+			// yang dibatalkan bila jurnal ditolak
+			err = f.db.Transaction(func(tx *gorm.DB) error { return postJournal(tx, &entry) })
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("postJournal() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := f.balance(t, wallet); toCents(got) != toCents(tt.wantBalance) {
+				t.Errorf("balance = %.2f, want %.2f", got, tt.wantBalance)
+			}
+		})
+	}
+
+	t.Run("jurnal tidak seimbang tidak disimpan", func(t *testing.T) {
+		f := newLedgerFixture(t)
+		wallet := f.wallet(t, models.Wallet{Name: "Tunai"}, 0)
+		entry := models.JournalEntry{
+			UserID:      f.user.ID,
+			HouseholdID: f.household.ID,
+			Kind:        models.JournalAdjustment,
+			EntryDate:   time.Now(),
+			Postings:    []models.Posting{{AccountID: f.account(t, wallet).ID, Debit: 100}},
+		}
+		if err := postJournal(f.db, &entry); !errors.Is(err, errUnbalancedJournal) {
+			t.Fatalf("postJournal() error = %v, want errUnbalancedJournal", err)
+		}
+		var count int64
+		f.db.Model(&models.JournalEntry{}).Where("kind = ?", models.JournalAdjustment).Count(&count)
+		if count != 0 {
+			t.Errorf("stored %d adjustment entries, want 0", count)
+		}
+	})
+}
+
+func TestVoidAndRestoreJournal(t *testing.T) {
+	f := newLedgerFixture(t)
+	bank := f.wallet(t, models.Wallet{Name: "Bank"}, 1000)
+	cash := f.wallet(t, models.Wallet{Name: "Tunai"}, 0)
+	entry := f.transfer(t, bank, cash, 400)
+
+	if err := voidJournal(f.db, entry); err != nil {
+		t.Fatalf("voidJournal() error = %v", err)
+	}
+	if got := f.balance(t, bank); got != 1000 {
+		t.Errorf("bank balance after void = %.2f, want 1000", got)
+	}
+	if got := f.balance(t, cash); got != 0 {
+		t.Errorf("cash balance after void = %.2f, want 0", got)
+	}
+
+	var voided models.JournalEntry
+	if err := f.db.Unscoped().First(&voided, entry.ID).Error; err != nil {
+		t.Fatalf("load voided entry: %v", err)
+	}
+	if !voided.DeletedAt.Valid {
+		t.Error("voided entry is not in the trash")
+	}
+	accounts, err := voidedAccounts(voided)
+	if err != nil {
+		t.Fatalf("voidedAccounts() error = %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Errorf("voidedAccounts() = %v, want both wallet accounts", accounts)
+	}
+
+	if err := restoreJournal(f.db, voided); err != nil {
+		t.Fatalf("restoreJournal() error = %v", err)
+	}
+	if got := f.balance(t, bank); got != 600 {
+		t.Errorf("bank balance after restore = %.2f, want 600", got)
+	}
+	if got := f.balance(t, cash); got != 400 {
+		t.Errorf("cash balance after restore = %.2f, want 400", got)
+	}
+	var restored models.JournalEntry
+	if err := f.db.First(&restored, entry.ID).Error; err != nil {
+		t.Fatalf("restored entry not found: %v", err)
+	}
+	if restored.VoidedPostings != nil {
+		t.Errorf("VoidedPostings = %s, want nil after restore", restored.VoidedPostings)
+	}
+}
+
+func TestRestoreJournalChecksLimits(t *testing.T) {
+	f := newLedgerFixture(t)
+	card := f.wallet(t, models.Wallet{Name: "Kartu", Type: models.WalletCreditCard, CreditLimit: floatPtr(1000)}, 0)
+	cash := f.wallet(t, models.Wallet{Name: "Tunai"}, 0)
+	entry := f.transfer(t, card, cash, 600)
+	if err := voidJournal(f.db, entry); err != nil {
+		t.Fatalf("voidJournal() error = %v", err)
+	}
+
+	// Limit kartu terpakai lagi selama jurnalnya di tempat sampah
+	f.transfer(t, card, cash, 700)
+	f.db.Unscoped().First(&entry, entry.ID)
+	err := f.db.Transaction(func(tx *gorm.DB) error { return restoreJournal(tx, entry) })
+	if !errors.Is(err, errCreditLimitExceeded) {
+		t.Fatalf("restoreJournal() error = %v, want errCreditLimitExceeded", err)
+	}
+	if got := f.balance(t, card); got != -700 {
+		t.Errorf("card balance = %.2f, want -700", got)
+	}
+}
+
+func TestUnpostTransaction(t *testing.T) {
+	t.Run("jurnal transaksi dihapus", func(t *testing.T) {
+		f := newLedgerFixture(t)
+		wallet := f.wallet(t, models.Wallet{Name: "Tunai"}, 500)
+		food := f.category(t, "Makan", "expense")
+		transaction := f.transaction(t, wallet, food, 120)
+		if got := f.balance(t, wallet); got != 380 {
+			t.Fatalf("balance after expense = %.2f, want 380", got)
+		}
+
+		if err := unpostTransaction(f.db, transaction); err != nil {
+			t.Fatalf("unpostTransaction() error = %v", err)
+		}
+		if got := f.balance(t, wallet); got != 500 {
+			t.Errorf("balance after unpost = %.2f, want 500", got)
+		}
+		var entries int64
+		f.db.Unscoped().Model(&models.JournalEntry{}).Where("transaction_id = ?", transaction.ID).Count(&entries)
+		if entries != 0 {
+			t.Errorf("%d journal entries left for the transaction, want 0", entries)
+		}
+	})
+
+	t.Run("transaksi lama tanpa jurnal dibatalkan dengan penyesuaian", func(t *testing.T) {
+		f := newLedgerFixture(t)
+		wallet := f.wallet(t, models.Wallet{Name: "Tunai"}, 500)
+		salary := f.category(t, "Gaji", "income")
+		transaction := f.transaction(t, wallet, salary, 300)
+		// Tiru transaksi dari sebelum buku besar ada: saldonya sudah masuk,
+		// tetapi tidak ada jurnal yang merujuknya
+		f.db.Model(&models.JournalEntry{}).Where("transaction_id = ?", transaction.ID).Update("transaction_id", nil)
+
+		if err := unpostTransaction(f.db, transaction); err != nil {
+			t.Fatalf("unpostTransaction() error = %v", err)
+		}
+		if got := f.balance(t, wallet); got != 500 {
+			t.Errorf("balance after unpost = %.2f, want 500", got)
+		}
+		var adjustment models.JournalEntry
+		err := f.db.Preload("Postings").Where("kind = ?", models.JournalAdjustment).First(&adjustment).Error
+		if err != nil {
+			t.Fatalf("adjustment entry not found: %v", err)
+		}
+		if want := fmt.Sprintf("Pembatalan: %s", transaction.Description); adjustment.Description != want {
+			t.Errorf("adjustment description = %q, want %q", adjustment.Description, want)
+		}
+		if adjustment.TransactionID != nil {
+			t.Errorf("adjustment TransactionID = %d, want nil", *adjustment.TransactionID)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAllAccounts: Mendapatkan semua akun buku besar milik user
+func GetAllAccounts(c *gin.Context) {
+	var accounts []models.Account
+	db := c.MustGet("db").(*gorm.DB)
+
+	// Akun dompet dan kategori yang ada di tempat sampah tidak ditampilkan
+	db.Scopes(tenantScope(c)).
+		Where("wallet_id IS NULL OR wallet_id IN (?)", db.Model(&models.Wallet{}).Select("id")).
+		Where("category_id IS NULL OR category_id IN (?)", db.Model(&models.Category{}).Select("id")).
+		Order("type, name").Find(&accounts)
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// GetJournalEntries: Mendapatkan jurnal beserta posting-nya, bisa difilter per akun
+func GetJournalEntries(c *gin.Context) {
+	var entries []models.JournalEntry
+	db := c.MustGet("db").(*gorm.DB)
+
+	query := db.Preload("Postings.Account").Scopes(tenantScope(c))
+	if accountID := c.Query("account_id"); accountID != "" {
+		query = query.Where("id IN (?)", db.Model(&models.Posting{}).Select("journal_entry_id").Where("account_id = ?", accountID))
+	}
+	query.Order("entry_date desc, id desc").Find(&entries)
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+type TrialBalanceRow struct {
+	AccountID uint    `json:"account_id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
+}
+
+// GetTrialBalance: Neraca saldo per tanggal (default hari ini)
+func GetTrialBalance(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	asOf := time.Now()
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+			return
+		}
+		asOf = parsed
+	}
+
+	var rows []TrialBalanceRow
+	db.Table("postings").
+		Select("accounts.id AS account_id, accounts.name, accounts.type, SUM(postings.debit) AS debit, SUM(postings.credit) AS credit").
+		Joins("JOIN accounts ON accounts.id = postings.account_id").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("accounts.household_id = ? AND journal_entries.entry_date <= ?", getCurrentHousehold(c).ID, asOf).
+		Group("accounts.id, accounts.name, accounts.type").
+		Order("accounts.type, accounts.name").
+		Scan(&rows)
+
+	var totalDebit, totalCredit float64
+	for _, row := range rows {
+		totalDebit += row.Debit
+		totalCredit += row.Credit
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         rows,
+		"total_debit":  totalDebit,
+		"total_credit": totalCredit,
+		"balanced":     toCents(totalDebit) == toCents(totalCredit),
+	})
+}
+
+type TransferInput struct {
+	FromWalletID uint      `json:"from_wallet_id" binding:"required"`
+	ToWalletID   uint      `json:"to_wallet_id" binding:"required,nefield=FromWalletID"`
+	Amount       float64   `json:"amount" binding:"required,gt=0"`
+	Description  string    `json:"description"`
+	TransferDate time.Time `json:"transfer_date" binding:"required"`
+}
+
+// CreateTransfer: Memindahkan dana antar dompet milik user
+func CreateTransfer(c *gin.Context) {
+	var input TransferInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Transfer butuh peran editor pada kedua dompet
+	from, ok := loadWalletWithRole(c, db, input.FromWalletID, models.RoleEditor)
+	if !ok {
+		return
+	}
+	to, ok := loadWalletWithRole(c, db, input.ToWalletID, models.RoleEditor)
+	if !ok {
+		return
+	}
+	if from.ArchivedAt != nil || to.ArchivedAt != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errWalletArchived.Error()})
+		return
+	}
+	if from.HouseholdID != to.HouseholdID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfers between wallets of different households are not supported"})
+		return
+	}
+	if from.Currency != to.Currency {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfers between wallets with different currencies are not supported"})
+		return
+	}
+
+	entry := models.JournalEntry{
+		UserID:      currentUser.ID,
+		HouseholdID: from.HouseholdID,
+		Kind:        models.JournalTransfer,
+		Description: input.Description,
+		EntryDate:   input.TransferDate,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		fromAcc, err := walletAccount(tx, from)
+		if err != nil {
+			return err
+		}
+		toAcc, err := walletAccount(tx, to)
+		if err != nil {
+			return err
+		}
+		entry.Postings = movePostings(toAcc.ID, fromAcc.ID, input.Amount)
+		return postJournal(tx, &entry)
+	})
+
+	if isWalletRuleError(err) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transfer failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entry, "message": "Transfer created successfully"})
+}
+
+// DeleteTransfer: Membatalkan transfer dan mengembalikan saldo kedua dompet
+func DeleteTransfer(c *gin.Context) {
+	var entry models.JournalEntry
+	db := c.MustGet("db").(*gorm.DB)
+
+	if err := db.Where("id = ? AND kind = ?", c.Param("id"), models.JournalTransfer).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	// Pastikan user editor pada setiap dompet yang terlibat
+	var walletIDs []uint
+	db.Model(&models.Account{}).
+		Where("id IN (?) AND wallet_id IS NOT NULL", db.Model(&models.Posting{}).Select("account_id").Where("journal_entry_id = ?", entry.ID)).
+		Pluck("wallet_id", &walletIDs)
+	for _, walletID := range walletIDs {
+		if _, ok := loadWalletWithRole(c, db, walletID, models.RoleEditor); !ok {
+			return
+		}
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error { return deleteJournal(tx, entry) }); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transfer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Transfer deleted successfully"})
+}
@@ -1,8 +1,14 @@
 package controllers
 
 import (
+	"bytes"
 	"dompet/backend/models"
+	"dompet/backend/utils"
+	"fmt"
+	"image"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -30,9 +36,9 @@ func UpdateUserDetails(c *gin.Context) {
 }
 
 type UpdateProfileInput struct {
-	Name            string `json:"name,omitempty"`
-	Currency        string `json:"currency,omitempty"`
-	ProfileImageURL string `json:"profile_image_url,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Language string `json:"language,omitempty" binding:"omitempty,max=10"`
 }
 
 func UpdateProfile(c *gin.Context) {
@@ -44,6 +50,10 @@ func UpdateProfile(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if input.Language != "" && !utils.IsSupportedLanguage(input.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "language must be one of: " + strings.Join(utils.SupportedLanguages(), ", ")})
+		return
+	}
 
 	updateData := make(map[string]interface{})
 	if input.Name != "" {
@@ -52,11 +62,13 @@ func UpdateProfile(c *gin.Context) {
 	if input.Currency != "" {
 		updateData["currency"] = input.Currency
 	}
-
-	if _, ok := c.GetPostForm("profile_image_url"); ok || input.ProfileImageURL != "" {
-		updateData["profile_image_url"] = input.ProfileImageURL
+	if input.Language != "" {
+		updateData["language"] = input.Language
 	}
 
+	// Foto profil tidak diubah di sini; hanya diatur lewat
+	// POST /api/profile/avatar dan DELETE /api/profile/avatar
+
 	if len(updateData) > 0 {
 		if err := db.Model(&currentUser).Updates(updateData).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
@@ -99,3 +111,173 @@ func ChangePassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
 }
+
+const (
+	maxAvatarSize      = 5 << 20 // 5 MB
+	minAvatarDimension = 64
+	maxAvatarDimension = 8000
+	// avatarURLPrefix adalah path publik tempat avatar disajikan
+	avatarURLPrefix = "/avatars/"
+)
+
+// avatarSizes adalah ukuran (px, persegi) varian avatar yang dibuat.
+// ProfileImageURL menunjuk ke varian defaultAvatarSize.
+var avatarSizes = []int{512, 256, 64}
+
+const defaultAvatarSize = 256
+
+// avatarKey mengembalikan key blob satu varian avatar
+func avatarKey(userID uint, name string, size int) string {
+	return fmt.Sprintf("avatars/%d/%s_%d.jpg", userID, name, size)
+}
+
+// avatarBase mengembalikan bagian "<id>/<nama acak>" dari url bila url
+// persis berbentuk /avatars/<id>/<nama acak>_<ukuran>.jpg milik user
+func avatarBase(user models.User, url string) (string, bool) {
+	rest, ok := strings.CutPrefix(url, fmt.Sprintf("%s%d/", avatarURLPrefix, user.ID))
+	if !ok {
+		return "", false
+	}
+	file, ok := strings.CutSuffix(rest, ".jpg")
+	if !ok {
+		return "", false
+	}
+	sep := strings.LastIndex(file, "_")
+	if sep == -1 {
+		return "", false
+	}
+	name, size := file[:sep], file[sep+1:]
+	if len(name) != 32 || strings.Trim(name, "0123456789abcdef") != "" {
+		return "", false
+	}
+	if !isAvatarSize(size) {
+		return "", false
+	}
+	return fmt.Sprintf("%d/%s", user.ID, name), true
+}
+
+// isAvatarSize bernilai true bila size adalah salah satu avatarSizes
+func isAvatarSize(size string) bool {
+	for _, s := range avatarSizes {
+		if strconv.Itoa(s) == size {
+			return true
+		}
+	}
+	return false
+}
+
+// avatarVariantKeys mengembalikan key semua varian dari avatar user saat ini
+func avatarVariantKeys(user models.User) []string {
+	base, ok := avatarBase(user, user.ProfileImageURL)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(avatarSizes))
+	for _, size := range avatarSizes {
+		keys = append(keys, fmt.Sprintf("avatars/%s_%d.jpg", base, size))
+	}
+	return keys
+}
+
+// UploadAvatar: Mengunggah foto profil. Gambar divalidasi, diputar sesuai
+// orientasi EXIF, dipotong persegi, lalu disimpan ulang sebagai JPEG dalam
+// beberapa ukuran sehingga metadata EXIF (lokasi, kamera) ikut terbuang.
+func UploadAvatar(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+	blobs := getBlobStore(c)
+
+	data, contentType, _, ok := readUpload(c, maxAvatarSize)
+	if !ok {
+		return
+	}
+	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/gif" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Avatar must be a JPEG, PNG or GIF image"})
+		return
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image could not be read"})
+		return
+	}
+	if config.Width < minAvatarDimension || config.Height < minAvatarDimension ||
+		config.Width > maxAvatarDimension || config.Height > maxAvatarDimension {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Avatar must be between %d and %d pixels on each side", minAvatarDimension, maxAvatarDimension)})
+		return
+	}
+	img, err := decodeImage(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image could not be read: " + err.Error()})
+		return
+	}
+	if contentType == "image/jpeg" {
+		img = utils.ApplyOrientation(img, utils.JPEGOrientation(data))
+	}
+	square := utils.CropSquare(img)
+
+	name := randomName()
+	stored := make([]string, 0, len(avatarSizes))
+	variants := gin.H{}
+	for _, size := range avatarSizes {
+		encoded, err := encodeJPEG(utils.ResizeToFit(square, size, size))
+		if err == nil {
+			err = blobs.Put(c.Request.Context(), avatarKey(currentUser.ID, name, size), encoded, "image/jpeg")
+		}
+		if err != nil {
+			deleteBlobs(c, stored...)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store avatar"})
+			return
+		}
+		stored = append(stored, avatarKey(currentUser.ID, name, size))
+		variants[strconv.Itoa(size)] = "/" + avatarKey(currentUser.ID, name, size)
+	}
+
+	previous := avatarVariantKeys(currentUser)
+	url := "/" + avatarKey(currentUser.ID, name, defaultAvatarSize)
+	if err := db.Model(&currentUser).Update("profile_image_url", url).Error; err != nil {
+		deleteBlobs(c, stored...)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+	deleteBlobs(c, previous...)
+
+	c.JSON(http.StatusOK, gin.H{"data": currentUser, "variants": variants})
+}
+
+// DeleteAvatar: Menghapus foto profil
+func DeleteAvatar(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	previous := avatarVariantKeys(currentUser)
+	if err := db.Model(&currentUser).Update("profile_image_url", "").Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+	deleteBlobs(c, previous...)
+
+	c.JSON(http.StatusOK, gin.H{"data": currentUser})
+}
+
+// ServeAvatar: Menyajikan file avatar secara publik agar bisa dipakai
+// langsung di tag <img>. Nama file acak, sehingga aman di-cache lama.
+func ServeAvatar(c *gin.Context) {
+	key := "avatars/" + strings.TrimPrefix(c.Param("path"), "/")
+	if !strings.HasSuffix(key, ".jpg") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Avatar not found"})
+		return
+	}
+
+	reader, err := getBlobStore(c).Get(c.Request.Context(), key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Avatar not found"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "image/jpeg", reader, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errReconciliationUnbalanced dikembalikan bila saldo cleared belum sama
+// dengan saldo rekening koran
+var errReconciliationUnbalanced = errors.New("cleared balance does not match the statement balance")
+
+type ReconciliationInput struct {
+	StatementDate time.Time `json:"statement_date" binding:"required"`
+	// Saldo akhir rekening koran. Untuk kartu kredit/pinjaman diisi sebagai
+	// jumlah utang (positif), sama seperti yang tertera di tagihan.
+	StatementBalance *float64 `json:"statement_balance" binding:"required"`
+}
+
+type ReconcileTransactionsInput struct {
+	TransactionIDs []uint `json:"transaction_ids" binding:"required,min=1"`
+	Cleared        bool   `json:"cleared"`
+}
+
+// ReconciliationSummary adalah kondisi rekonsiliasi beserta transaksi yang
+// bisa dicentang
+type ReconciliationSummary struct {
+	models.Reconciliation
+	ClearedBalance float64              `json:"cleared_balance"`
+	Difference     float64              `json:"difference"`
+	Transactions   []models.Transaction `json:"transactions"`
+}
+
+// statementBalanceFor mengubah saldo rekening koran ke tanda saldo dompet:
+// dompet liabilitas menyimpan utang sebagai saldo negatif
+func statementBalanceFor(wallet models.Wallet, amount float64) float64 {
+	if wallet.IsLiability() {
+		return -amount
+	}
+	return amount
+}
+
+// clearedBalance menghitung saldo dompet sampai tanggal rekening koran tanpa
+// transaksi yang masih pending
+func clearedBalance(db *gorm.DB, wallet models.Wallet, statementDate time.Time) (float64, error) {
+	account, err := walletAccount(db, wallet)
+	if err != nil {
+		return 0, err
+	}
+	pending := db.Model(&models.Transaction{}).Select("id").
+		Where("wallet_id = ? AND status = ?", wallet.ID, models.TransactionPending)
+
+	var balance float64
+	err = db.Table("postings").
+		Select("COALESCE(SUM(postings.debit - postings.credit), 0)").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("postings.account_id = ? AND journal_entries.entry_date <= ?", account.ID, statementDate).
+		Where("journal_entries.transaction_id IS NULL OR journal_entries.transaction_id NOT IN (?)", pending).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// loadReconciliation mencari rekonsiliasi berdasarkan :id dan memastikan user
+// punya minimal peran minRole pada dompetnya
+func loadReconciliation(c *gin.Context, db *gorm.DB, minRole string) (models.Reconciliation, models.Wallet, bool) {
+	var reconciliation models.Reconciliation
+	if err := db.First(&reconciliation, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reconciliation not found"})
+		return reconciliation, models.Wallet{}, false
+	}
+	wallet, ok := loadWalletWithRole(c, db, reconciliation.WalletID, minRole)
+	return reconciliation, wallet, ok
+}
+
+// loadInProgressReconciliation seperti loadReconciliation, tetapi menolak
+// rekonsiliasi yang sudah selesai
+func loadInProgressReconciliation(c *gin.Context, db *gorm.DB) (models.Reconciliation, models.Wallet, bool) {
+	reconciliation, wallet, ok := loadReconciliation(c, db, models.RoleEditor)
+	if ok && reconciliation.Status != models.ReconciliationInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reconciliation is already completed"})
+		return reconciliation, wallet, false
+	}
+	return reconciliation, wallet, ok
+}
+
+// reconciliationSummary menyusun saldo cleared, selisih dan daftar transaksi
+// untuk sebuah rekonsiliasi
+func reconciliationSummary(db *gorm.DB, reconciliation models.Reconciliation, wallet models.Wallet) (ReconciliationSummary, error) {
+	summary := ReconciliationSummary{Reconciliation: reconciliation}
+
+	query := db.Preload("Category").Where("wallet_id = ? AND transaction_date <= ?", wallet.ID, reconciliation.StatementDate)
+	if reconciliation.Status == models.ReconciliationCompleted {
+		query = query.Where("reconciliation_id = ?", reconciliation.ID)
+	} else {
+		query = query.Where("status <> ?", models.TransactionReconciled)
+	}
+	if err := query.Order("transaction_date, id").Find(&summary.Transactions).Error; err != nil {
+		return summary, err
+	}
+
+	balance, err := clearedBalance(db, wallet, reconciliation.StatementDate)
+	if err != nil {
+		return summary, err
+	}
+	summary.ClearedBalance = balance
+	summary.Difference = float64(toCents(reconciliation.StatementBalance)-toCents(balance)) / 100
+	return summary, nil
+}
+
+// StartReconciliation: Memulai rekonsiliasi dompet dengan saldo akhir dan
+// tanggal rekening koran
+func StartReconciliation(c *gin.Context) {
+	var input ReconciliationInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleEditor)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing models.Reconciliation
+	err := db.Where("wallet_id = ? AND status = ?", wallet.ID, models.ReconciliationInProgress).First(&existing).Error
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This wallet already has a reconciliation in progress", "data": existing})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start reconciliation"})
+		return
+	}
+
+	reconciliation := models.Reconciliation{
+		WalletID:         wallet.ID,
+		UserID:           currentUser.ID,
+		StatementDate:    input.StatementDate,
+		StatementBalance: statementBalanceFor(wallet, *input.StatementBalance),
+		Status:           models.ReconciliationInProgress,
+	}
+	if err := db.Create(&reconciliation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start reconciliation"})
+		return
+	}
+
+	summary, err := reconciliationSummary(db, reconciliation, wallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reconciliation"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": summary})
+}
+
+// GetWalletReconciliations: Riwayat rekonsiliasi sebuah dompet
+func GetWalletReconciliations(c *gin.Context) {
+	var reconciliations []models.Reconciliation
+	db := c.MustGet("db").(*gorm.DB)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	db.Where("wallet_id = ?", wallet.ID).Order("statement_date DESC, id DESC").Find(&reconciliations)
+	c.JSON(http.StatusOK, gin.H{"data": reconciliations})
+}
+
+// GetReconciliation: Detail rekonsiliasi, termasuk saldo cleared, selisih
+// terhadap rekening koran dan transaksi yang bisa dicentang
+func GetReconciliation(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	reconciliation, wallet, ok := loadReconciliation(c, db, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	summary, err := reconciliationSummary(db, reconciliation, wallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reconciliation"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// ReconcileTransactions: Mencentang (cleared) atau membatalkan centang
+// (pending) transaksi dalam rekonsiliasi yang sedang berjalan
+func ReconcileTransactions(c *gin.Context) {
+	var input ReconcileTransactionsInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	reconciliation, wallet, ok := loadInProgressReconciliation(c, db)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	db.Model(&models.Transaction{}).
+		Where("id IN ? AND wallet_id = ? AND transaction_date <= ? AND status <> ?", input.TransactionIDs, wallet.ID, reconciliation.StatementDate, models.TransactionReconciled).
+		Count(&count)
+	if int(count) != len(input.TransactionIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Some transactions do not belong to this reconciliation"})
+		return
+	}
+
+	status := models.TransactionPending
+	if input.Cleared {
+		status = models.TransactionCleared
+	}
+	if err := db.Model(&models.Transaction{}).Where("id IN ?", input.TransactionIDs).
+		Updates(map[string]interface{}{"status": status, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transactions"})
+		return
+	}
+
+	summary, err := reconciliationSummary(db, reconciliation, wallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reconciliation"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// CompleteReconciliation: Menyelesaikan rekonsiliasi bila saldo cleared sudah
+// sama dengan rekening koran, lalu mengunci transaksi cleared sebagai
+// reconciled
+func CompleteReconciliation(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	reconciliation, wallet, ok := loadInProgressReconciliation(c, db)
+	if !ok {
+		return
+	}
+
+	var summary ReconciliationSummary
+	err := db.Transaction(func(tx *gorm.DB) error {
+		current, err := reconciliationSummary(tx, reconciliation, wallet)
+		if err != nil {
+			return err
+		}
+		if toCents(current.Difference) != 0 {
+			summary = current
+			return errReconciliationUnbalanced
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.Transaction{}).
+			Where("wallet_id = ? AND transaction_date <= ? AND status = ?", wallet.ID, reconciliation.StatementDate, models.TransactionCleared).
+			Updates(map[string]interface{}{"status": models.TransactionReconciled, "reconciliation_id": reconciliation.ID, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+		reconciliation.Status = models.ReconciliationCompleted
+		reconciliation.CompletedAt = &now
+		if err := tx.Model(&reconciliation).Select("Status", "CompletedAt").Updates(&reconciliation).Error; err != nil {
+			return err
+		}
+
+		summary, err = reconciliationSummary(tx, reconciliation, wallet)
+		return err
+	})
+	if errors.Is(err, errReconciliationUnbalanced) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "data": summary})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete reconciliation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// CancelReconciliation: Membatalkan rekonsiliasi yang sedang berjalan. Status
+// cleared/pending yang sudah dicentang tetap dipertahankan.
+func CancelReconciliation(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	reconciliation, _, ok := loadInProgressReconciliation(c, db)
+	if !ok {
+		return
+	}
+
+	db.Delete(&reconciliation)
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Reconciliation cancelled"})
+}
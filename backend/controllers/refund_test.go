@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"bytes"
+	"dompet/backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serve menjalankan handler seolah-olah request sudah melewati
+// AuthMiddleware dan HouseholdMiddleware dengan user dan household fixture
+func (f *ledgerFixture) serve(handler gin.HandlerFunc, req *http.Request, params ...gin.Param) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = req
+	c.Params = params
+	c.Set("db", f.db)
+	c.Set("currentUser", f.user)
+	c.Set("currentHousehold", f.household)
+	handler(c)
+	return recorder
+}
+
+func jsonRequest(t *testing.T, method, target string, body interface{}) *http.Request {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func idParam(id uint) gin.Param {
+	return gin.Param{Key: "id", Value: fmt.Sprint(id)}
+}
+
+func (f *ledgerFixture) refund(t *testing.T, expense models.Transaction, body gin.H) *httptest.ResponseRecorder {
+	t.Helper()
+	req := jsonRequest(t, http.MethodPost, fmt.Sprintf("/api/transactions/%d/refund", expense.ID), body)
+	return f.serve(RefundTransaction, req, idParam(expense.ID))
+}
+
+func TestRefundTransaction(t *testing.T) {
+	f := newLedgerFixture(t)
+	bank := f.wallet(t, models.Wallet{Name: "Bank"}, 1000)
+	cash := f.wallet(t, models.Wallet{Name: "Tunai"}, 0)
+	shopping := f.category(t, "Belanja", "expense")
+	salary := f.category(t, "Gaji", "income")
+	expense := f.transaction(t, bank, shopping, 300)
+
+	w := f.refund(t, expense, gin.H{"amount": 100})
+	if w.Code != http.StatusOK {
+		t.Fatalf("first refund status = %d, body %s", w.Code, w.Body)
+	}
+	var response struct{ Data models.Transaction }
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Type != "refund" || response.Data.RefundOfID == nil || *response.Data.RefundOfID != expense.ID {
+		t.Errorf("refund = %+v, want a refund of transaction %d", response.Data, expense.ID)
+	}
+	if got := f.balance(t, bank); got != 800 {
+		t.Errorf("bank balance after refund = %.2f, want 800", got)
+	}
+
+	if w := f.refund(t, expense, gin.H{"amount": 200.01}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("refund above the remaining amount status = %d, want 422", w.Code)
+	}
+	if w := f.refund(t, expense, gin.H{"amount": 200, "wallet_id": cash.ID}); w.Code != http.StatusOK {
+		t.Fatalf("refund to another wallet status = %d, body %s", w.Code, w.Body)
+	}
+	if got := f.balance(t, cash); got != 200 {
+		t.Errorf("cash balance = %.2f, want 200", got)
+	}
+	if w := f.refund(t, expense, gin.H{"amount": 0.01}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("refund of a fully refunded expense status = %d, want 422", w.Code)
+	}
+
+	// Refund mengurangi beban kategori, bukan menambah pemasukan
+	categoryAcc, _ := categoryAccount(f.db, shopping)
+	var expenseTotal struct{ Total float64 }
+	f.db.Model(&models.Posting{}).Select("COALESCE(SUM(debit - credit), 0) AS total").
+		Where("account_id = ?", categoryAcc.ID).Scan(&expenseTotal)
+	if expenseTotal.Total != 0 {
+		t.Errorf("expense account total = %.2f, want 0 after a full refund", expenseTotal.Total)
+	}
+
+	income := f.transaction(t, bank, salary, 500)
+	if w := f.refund(t, income, gin.H{"amount": 100}); w.Code != http.StatusBadRequest {
+		t.Errorf("refund of income status = %d, want 400", w.Code)
+	}
+
+	// Pengeluaran yang sudah direfund tidak bisa dihapus sebelum refund-nya
+	deleteExpense := func() *httptest.ResponseRecorder {
+		req := jsonRequest(t, http.MethodDelete, fmt.Sprintf("/api/transactions/%d", expense.ID), nil)
+		return f.serve(DeleteTransaction, req, idParam(expense.ID))
+	}
+	if w := deleteExpense(); w.Code != http.StatusConflict {
+		t.Fatalf("delete refunded expense status = %d, want 409", w.Code)
+	}
+	var refunds []models.Transaction
+	f.db.Where("refund_of_id = ?", expense.ID).Find(&refunds)
+	for _, refund := range refunds {
+		req := jsonRequest(t, http.MethodDelete, fmt.Sprintf("/api/transactions/%d", refund.ID), nil)
+		if w := f.serve(DeleteTransaction, req, idParam(refund.ID)); w.Code != http.StatusOK {
+			t.Fatalf("delete refund status = %d, body %s", w.Code, w.Body)
+		}
+	}
+	if w := deleteExpense(); w.Code != http.StatusOK {
+		t.Fatalf("delete expense status = %d, body %s", w.Code, w.Body)
+	}
+	if got := f.balance(t, bank); got != 1500 {
+		t.Errorf("bank balance after deleting the expense = %.2f, want 1500", got)
+	}
+	if got := f.balance(t, cash); got != 0 {
+		t.Errorf("cash balance after deleting its refund = %.2f, want 0", got)
+	}
+}
+
+func TestRefundSplits(t *testing.T) {
+	f := newLedgerFixture(t)
+	bank := f.wallet(t, models.Wallet{Name: "Bank"}, 0)
+	food := f.category(t, "Makan", "expense")
+	home := f.category(t, "Rumah", "expense")
+	expense := f.transaction(t, bank, food, 100)
+	f.mustCreate(t, &[]models.TransactionSplit{
+		{TransactionID: expense.ID, CategoryID: food.ID, Amount: 75},
+		{TransactionID: expense.ID, CategoryID: home.ID, Amount: 25},
+	})
+
+	tests := []struct {
+		name   string
+		amount float64
+		want   map[uint]float64
+	}{
+		{name: "proporsional", amount: 40, want: map[uint]float64{food.ID: 30, home.ID: 10}},
+		{name: "sisa sen ke porsi terbesar", amount: 0.1, want: map[uint]float64{food.ID: 0.08, home.ID: 0.02}},
+		{name: "porsi nol dilewati", amount: 0.01, want: map[uint]float64{food.ID: 0.01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits, err := refundSplits(f.db, expense, tt.amount)
+			if err != nil {
+				t.Fatalf("refundSplits() error = %v", err)
+			}
+			got := map[uint]float64{}
+			for _, split := range splits {
+				got[split.CategoryID] = split.Amount
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("refundSplits(%.2f) = %v, want %v", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteWalletMove(t *testing.T) {
+	merge := func(f *ledgerFixture, source, target models.Wallet) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/wallets/%d?mode=move&target_wallet_id=%d", source.ID, target.ID), nil)
+		return f.serve(DeleteWallet, req, idParam(source.ID))
+	}
+
+	t.Run("riwayat dan saldo pindah ke dompet target", func(t *testing.T) {
+		f := newLedgerFixture(t)
+		cash := f.wallet(t, models.Wallet{Name: "Tunai"}, 500)
+		bank := f.wallet(t, models.Wallet{Name: "Bank"}, 1000)
+		expense := f.transaction(t, cash, f.category(t, "Makan", "expense"), 100)
+		f.transfer(t, cash, bank, 50)
+
+		if w := merge(f, cash, bank); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		// Transfer antara keduanya dihapus, lalu saldo Tunai (400) pindah
+		if got := f.balance(t, bank); got != 1400 {
+			t.Errorf("target balance = %.2f, want 1400", got)
+		}
+		var moved models.Transaction
+		f.db.First(&moved, expense.ID)
+		if moved.WalletID != bank.ID || moved.Version != expense.Version+1 {
+			t.Errorf("expense wallet = %d version = %d, want wallet %d version %d", moved.WalletID, moved.Version, bank.ID, expense.Version+1)
+		}
+		var transfers int64
+		f.db.Unscoped().Model(&models.JournalEntry{}).Where("kind = ?", models.JournalTransfer).Count(&transfers)
+		if transfers != 0 {
+			t.Errorf("%d transfer entries left, want 0", transfers)
+		}
+		var remaining int64
+		f.db.Unscoped().Model(&models.Wallet{}).Where("id = ?", cash.ID).Count(&remaining)
+		if remaining != 0 {
+			t.Error("source wallet was not purged")
+		}
+	})
+
+	t.Run("transaksi terekonsiliasi menolak penggabungan", func(t *testing.T) {
+		f := newLedgerFixture(t)
+		cash := f.wallet(t, models.Wallet{Name: "Tunai"}, 500)
+		bank := f.wallet(t, models.Wallet{Name: "Bank"}, 1000)
+		expense := f.transaction(t, cash, f.category(t, "Makan", "expense"), 100)
+		f.db.Model(&expense).Update("status", models.TransactionReconciled)
+
+		if w := merge(f, cash, bank); w.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409", w.Code)
+		}
+		if got := f.balance(t, cash); got != 400 {
+			t.Errorf("source balance = %.2f, want 400", got)
+		}
+		if got := f.balance(t, bank); got != 1000 {
+			t.Errorf("target balance = %.2f, want 1000", got)
+		}
+	})
+
+	t.Run("saldo gabungan melewati limit kartu target", func(t *testing.T) {
+		f := newLedgerFixture(t)
+		oldCard := f.wallet(t, models.Wallet{Name: "Kartu Lama", Type: models.WalletCreditCard, CreditLimit: floatPtr(1000)}, -800)
+		newCard := f.wallet(t, models.Wallet{Name: "Kartu Baru", Type: models.WalletCreditCard, CreditLimit: floatPtr(1000)}, -500)
+
+		if w := merge(f, oldCard, newCard); w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want 422", w.Code)
+		}
+		if got := f.balance(t, oldCard); got != -800 {
+			t.Errorf("source balance = %.2f, want -800", got)
+		}
+		if got := f.balance(t, newCard); got != -500 {
+			t.Errorf("target balance = %.2f, want -500", got)
+		}
+	})
+}
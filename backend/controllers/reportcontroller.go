@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CategoryReportRow adalah total transaksi satu kategori. Total sudah
+// termasuk semua subkategorinya, sedangkan OwnTotal hanya transaksi yang
+// dicatat langsung di kategori tersebut.
+type CategoryReportRow struct {
+	CategoryID uint                `json:"category_id"`
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	Currency   string              `json:"currency"`
+	OwnTotal   float64             `json:"own_total"`
+	Total      float64             `json:"total"`
+	Children   []CategoryReportRow `json:"children,omitempty"`
+}
+
+// reportDateRange menerapkan filter ?from= dan ?to= (YYYY-MM-DD) pada
+// tanggal transaksi, dan menulis respons 400 bila formatnya salah
+func reportDateRange(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	for _, param := range []struct{ name, op string }{{"from", ">="}, {"to", "<="}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param.name + " date, use YYYY-MM-DD"})
+			return query, false
+		}
+		query = query.Where("transactions.transaction_date "+param.op+" ?", date)
+	}
+	return query, true
+}
+
+type categoryTotal struct {
+	CategoryID uint
+	Currency   string
+	Total      float64
+}
+
+// GetCategoryReport: Total transaksi per kategori pada rentang ?from= dan
+// ?to= (YYYY-MM-DD). Total subkategori dijumlahkan ke induknya sehingga
+// kategori utama menampilkan total keseluruhan cabangnya.
+func GetCategoryReport(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	// Transaksi yang dipecah dihitung per baris rinciannya; refund mengurangi
+	// total kategori pengeluarannya
+	query := db.Table("transactions").
+		Select("COALESCE(transaction_splits.category_id, transactions.category_id) AS category_id, wallets.currency, "+
+			"SUM(CASE WHEN transactions.type = 'refund' THEN -1 ELSE 1 END * COALESCE(transaction_splits.amount, transactions.amount)) AS total").
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		Joins("JOIN wallets ON wallets.id = transactions.wallet_id").
+		Where("transactions.household_id = ? AND transactions.deleted_at IS NULL", getCurrentHousehold(c).ID).
+		Group("COALESCE(transaction_splits.category_id, transactions.category_id), wallets.currency")
+
+	query, ok := reportDateRange(c, query)
+	if !ok {
+		return
+	}
+
+	var totals []categoryTotal
+	if err := query.Scan(&totals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	var categories []models.Category
+	db.Scopes(tenantScope(c)).Order("name").Find(&categories)
+
+	// Satu pohon laporan per mata uang agar nominal tidak tercampur
+	own := map[string]map[uint]float64{}
+	for _, t := range totals {
+		if own[t.Currency] == nil {
+			own[t.Currency] = map[uint]float64{}
+		}
+		own[t.Currency][t.CategoryID] += t.Total
+	}
+
+	currencies := make([]string, 0, len(own))
+	for currency := range own {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	report := []CategoryReportRow{}
+	for _, currency := range currencies {
+		for _, node := range buildCategoryTree(categories) {
+			row := categoryReportRow(node, currency, own[currency])
+			if row.Total != 0 {
+				report = append(report, row)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// categoryReportRow menghitung total satu kategori beserta subkategorinya
+func categoryReportRow(category models.Category, currency string, own map[uint]float64) CategoryReportRow {
+	row := CategoryReportRow{
+		CategoryID: category.ID,
+		Name:       category.Name,
+		Type:       category.Type,
+		Currency:   currency,
+		OwnTotal:   own[category.ID],
+		Total:      own[category.ID],
+	}
+	for _, child := range category.Children {
+		childRow := categoryReportRow(child, currency, own)
+		if childRow.Total == 0 {
+			continue
+		}
+		row.Total += childRow.Total
+		row.Children = append(row.Children, childRow)
+	}
+	return row
+}
+
+type TagReportRow struct {
+	TagID    uint    `json:"tag_id"`
+	Name     string  `json:"name"`
+	Currency string  `json:"currency"`
+	Income   float64 `json:"income"`
+	Expense  float64 `json:"expense"`
+	Count    int64   `json:"count"`
+}
+
+// GetTagReport: Total pemasukan dan pengeluaran per tag pada rentang ?from=
+// dan ?to=, dipisah per mata uang. Satu transaksi bisa dihitung di beberapa
+// tag sekaligus.
+func GetTagReport(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	query := db.Table("transaction_tags").
+		Select(`tags.id AS tag_id, tags.name, wallets.currency,
+			SUM(CASE WHEN transactions.type = 'income' THEN transactions.amount ELSE 0 END) AS income,
+			SUM(CASE WHEN transactions.type = 'expense' THEN transactions.amount
+				WHEN transactions.type = 'refund' THEN -transactions.amount ELSE 0 END) AS expense,
+			COUNT(*) AS count`).
+		Joins("JOIN tags ON tags.id = transaction_tags.tag_id").
+		Joins("JOIN transactions ON transactions.id = transaction_tags.transaction_id").
+		Joins("JOIN wallets ON wallets.id = transactions.wallet_id").
+		Where("tags.household_id = ? AND transactions.deleted_at IS NULL", getCurrentHousehold(c).ID).
+		Group("tags.id, tags.name, wallets.currency").
+		Order("tags.name, wallets.currency")
+
+	query, ok := reportDateRange(c, query)
+	if !ok {
+		return
+	}
+
+	report := []TagReportRow{}
+	if err := query.Scan(&report).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,433 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RuleInput struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Priority int    `json:"priority"`
+	Enabled  *bool  `json:"enabled"`
+
+	DescriptionContains string   `json:"description_contains"`
+	DescriptionRegex    string   `json:"description_regex"`
+	MinAmount           *float64 `json:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount           *float64 `json:"max_amount" binding:"omitempty,gte=0"`
+	WalletID            *uint    `json:"wallet_id"`
+
+	SetCategoryID     *uint  `json:"set_category_id"`
+	RenameDescription string `json:"rename_description"`
+	AddTagID          *uint  `json:"add_tag_id"`
+}
+
+// ruleOutcome adalah hasil evaluasi aturan terhadap satu transaksi
+type ruleOutcome struct {
+	CategoryID     *uint  `json:"category_id,omitempty"`
+	Description    string `json:"description"`
+	TagIDs         []uint `json:"tag_ids"`
+	MatchedRuleIDs []uint `json:"matched_rule_ids"`
+}
+
+// evaluateRules menjalankan aturan sesuai urutan prioritas. Untuk kategori
+// dan deskripsi, aturan pertama yang cocok yang dipakai; tag dari semua
+// aturan yang cocok dikumpulkan.
+func evaluateRules(rules []models.TransactionRule, description string, amount float64, walletID uint) ruleOutcome {
+	outcome := ruleOutcome{Description: description, TagIDs: []uint{}, MatchedRuleIDs: []uint{}}
+	renamed := false
+	for _, rule := range rules {
+		if !rule.Matches(description, amount, walletID) {
+			continue
+		}
+		outcome.MatchedRuleIDs = append(outcome.MatchedRuleIDs, rule.ID)
+		if rule.SetCategoryID != nil && outcome.CategoryID == nil {
+			outcome.CategoryID = rule.SetCategoryID
+		}
+		if rule.RenameDescription != "" && !renamed {
+			outcome.Description = rule.RenameDescription
+			renamed = true
+		}
+		if rule.AddTagID != nil && !containsID(outcome.TagIDs, *rule.AddTagID) {
+			outcome.TagIDs = append(outcome.TagIDs, *rule.AddTagID)
+		}
+	}
+	return outcome
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// householdRules memuat aturan aktif household sesuai urutan evaluasinya
+func householdRules(db *gorm.DB, householdID uint) []models.TransactionRule {
+	var rules []models.TransactionRule
+	db.Scopes(householdScope(householdID)).Where("enabled = ?", true).Order("priority, id").Find(&rules)
+	return rules
+}
+
+// validateRule memastikan aturan punya kondisi dan aksi yang valid, dan semua
+// referensinya berada di household aturan
+func validateRule(db *gorm.DB, rule models.TransactionRule) error {
+	if rule.DescriptionContains == "" && rule.DescriptionRegex == "" && rule.MinAmount == nil && rule.MaxAmount == nil && rule.WalletID == nil {
+		return errors.New("a rule needs at least one condition")
+	}
+	if rule.SetCategoryID == nil && rule.RenameDescription == "" && rule.AddTagID == nil {
+		return errors.New("a rule needs at least one action")
+	}
+	if rule.DescriptionRegex != "" {
+		if _, err := regexp.Compile(rule.DescriptionRegex); err != nil {
+			return errors.New("description_regex is not a valid regular expression")
+		}
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.New("min_amount cannot be greater than max_amount")
+	}
+	if rule.WalletID != nil {
+		var count int64
+		db.Model(&models.Wallet{}).Scopes(householdScope(rule.HouseholdID)).Where("id = ?", *rule.WalletID).Count(&count)
+		if count == 0 {
+			return errors.New("wallet not found in this household")
+		}
+	}
+	if rule.SetCategoryID != nil {
+		var count int64
+		db.Model(&models.Category{}).Scopes(householdScope(rule.HouseholdID)).Where("id = ?", *rule.SetCategoryID).Count(&count)
+		if count == 0 {
+			return errors.New("category not found in this household")
+		}
+	}
+	if rule.AddTagID != nil {
+		var count int64
+		db.Model(&models.Tag{}).Scopes(householdScope(rule.HouseholdID)).Where("id = ?", *rule.AddTagID).Count(&count)
+		if count == 0 {
+			return errors.New("tag not found in this household")
+		}
+	}
+	return nil
+}
+
+// applyRuleInput menyalin input ke aturan
+func applyRuleInput(rule *models.TransactionRule, input RuleInput) {
+	rule.Name = input.Name
+	rule.Priority = input.Priority
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	rule.DescriptionContains = input.DescriptionContains
+	rule.DescriptionRegex = input.DescriptionRegex
+	rule.MinAmount = input.MinAmount
+	rule.MaxAmount = input.MaxAmount
+	rule.WalletID = input.WalletID
+	rule.SetCategoryID = input.SetCategoryID
+	rule.RenameDescription = input.RenameDescription
+	rule.AddTagID = input.AddTagID
+}
+
+// loadRule mencari aturan di household aktif berdasarkan parameter URL
+func loadRule(c *gin.Context, db *gorm.DB) (models.TransactionRule, bool) {
+	var rule models.TransactionRule
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+		return rule, false
+	}
+	return rule, true
+}
+
+// GetAllRules: Mendapatkan semua aturan household aktif sesuai urutan evaluasi
+func GetAllRules(c *gin.Context) {
+	var rules []models.TransactionRule
+	db := c.MustGet("db").(*gorm.DB)
+
+	db.Scopes(tenantScope(c)).Preload("SetCategory").Preload("AddTag").Order("priority, id").Find(&rules)
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// CreateRule: Membuat aturan kategorisasi baru
+func CreateRule(c *gin.Context) {
+	var input RuleInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.TransactionRule{UserID: currentUser.ID, HouseholdID: getCurrentHousehold(c).ID, Enabled: true}
+	applyRuleInput(&rule, input)
+	if err := validateRule(db, rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create rule"})
+		return
+	}
+	// Nilai false tidak ikut di-INSERT karena kolom punya default true
+	if !rule.Enabled {
+		db.Model(&rule).Update("enabled", false)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// UpdateRule: Mengubah aturan kategorisasi
+func UpdateRule(c *gin.Context) {
+	var input RuleInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	rule, ok := loadRule(c, db)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyRuleInput(&rule, input)
+	if err := validateRule(db, rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Select("*").Omit("ID", "UserID", "HouseholdID", "CreatedAt").Updates(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+// DeleteRule: Menghapus aturan kategorisasi
+func DeleteRule(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	rule, ok := loadRule(c, db)
+	if !ok {
+		return
+	}
+
+	db.Delete(&rule)
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Rule deleted successfully"})
+}
+
+// RuleMatch adalah transaksi yang akan diubah oleh sebuah aturan
+type RuleMatch struct {
+	Transaction    models.Transaction `json:"transaction"`
+	NewCategoryID  uint               `json:"new_category_id"`
+	NewDescription string             `json:"new_description"`
+	AddTagID       *uint              `json:"add_tag_id,omitempty"`
+}
+
+// errRuleCategoryType dikembalikan bila kategori aturan berbeda tipe dengan
+// transaksi yang akan diubah
+var errRuleCategoryType = errors.New("rule category type does not match the transaction type")
+
+// ruleMatches mencari transaksi household yang cocok dengan aturan dan akan
+// berubah bila aturan diterapkan. Kondisi dompet, nominal dan teks disaring
+// di database; regex dicocokkan di Go. Kategori hanya diganti pada transaksi
+// yang tipenya sama dengan kategori aturan.
+func ruleMatches(db *gorm.DB, rule models.TransactionRule) ([]RuleMatch, error) {
+	// Transaksi yang sudah direkonsiliasi tidak diubah oleh aturan
+	query := db.Scopes(householdScope(rule.HouseholdID)).Where("status <> ?", models.TransactionReconciled)
+	if rule.WalletID != nil {
+		query = query.Where("wallet_id = ?", *rule.WalletID)
+	}
+	if rule.MinAmount != nil {
+		query = query.Where("amount >= ?", *rule.MinAmount)
+	}
+	if rule.MaxAmount != nil {
+		query = query.Where("amount <= ?", *rule.MaxAmount)
+	}
+	if rule.DescriptionContains != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(rule.DescriptionContains)
+		query = query.Where("description ILIKE ?", "%"+escaped+"%")
+	}
+
+	var categoryType string
+	if rule.SetCategoryID != nil {
+		// Kategori yang sudah dihapus tidak lagi diterapkan
+		var category models.Category
+		if err := db.First(&category, *rule.SetCategoryID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		categoryType = category.Type
+		// Aturan yang hanya mengganti kategori tidak berlaku untuk tipe lain
+		if rule.RenameDescription == "" && rule.AddTagID == nil {
+			query = query.Where("type = ?", categoryType)
+		}
+	}
+
+	var transactions []models.Transaction
+	if err := query.Preload("Tags").Preload("Splits").Order("transaction_date desc, id desc").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	matches := []RuleMatch{}
+	for _, transaction := range transactions {
+		outcome := evaluateRules([]models.TransactionRule{rule}, transaction.Description, transaction.Amount, transaction.WalletID)
+		if len(outcome.MatchedRuleIDs) == 0 {
+			continue
+		}
+		match := RuleMatch{Transaction: transaction, NewCategoryID: transaction.CategoryID, NewDescription: outcome.Description}
+		// Kategori transaksi yang dipecah ditentukan oleh baris rinciannya
+		if outcome.CategoryID != nil && len(transaction.Splits) == 0 && transaction.Type == categoryType {
+			match.NewCategoryID = *outcome.CategoryID
+		}
+		if len(outcome.TagIDs) > 0 && !transactionHasTag(transaction, outcome.TagIDs[0]) {
+			match.AddTagID = &outcome.TagIDs[0]
+		}
+		if match.NewCategoryID == transaction.CategoryID && match.NewDescription == transaction.Description && match.AddTagID == nil {
+			continue
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+func transactionHasTag(transaction models.Transaction, tagID uint) bool {
+	for _, tag := range transaction.Tags {
+		if tag.ID == tagID {
+			return true
+		}
+	}
+	return false
+}
+
+// TestRule: Menampilkan transaksi yang akan berubah bila aturan diterapkan,
+// tanpa menyimpan perubahan apa pun
+func TestRule(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	rule, ok := loadRule(c, db)
+	if !ok {
+		return
+	}
+
+	matches, err := ruleMatches(db, rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": matches, "count": len(matches)})
+}
+
+// ApplyRule: Menerapkan aturan ke transaksi yang sudah ada. Transaksi yang
+// kategorinya berubah dibukukan ulang agar saldo dompet tetap benar.
+func ApplyRule(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	rule, ok := loadRule(c, db)
+	if !ok {
+		return
+	}
+
+	var applied int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		matches, err := ruleMatches(tx, rule)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			if err := applyRuleMatch(tx, match); err != nil {
+				return err
+			}
+		}
+		applied = len(matches)
+		return nil
+	})
+	if isWalletRuleError(err) || errors.Is(err, errRuleCategoryType) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": applied, "message": "Rule applied successfully"})
+}
+
+// applyRuleMatch menyimpan perubahan aturan pada satu transaksi
+func applyRuleMatch(tx *gorm.DB, match RuleMatch) error {
+	transaction := match.Transaction
+	if match.AddTagID != nil {
+		var tag models.Tag
+		if err := tx.First(&tag, *match.AddTagID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&transaction).Association("Tags").Append(&tag); err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&transaction).UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+		return err
+	}
+	if match.NewCategoryID == transaction.CategoryID && match.NewDescription == transaction.Description {
+		return nil
+	}
+
+	if err := learnTransaction(tx, transaction, -1); err != nil {
+		return err
+	}
+
+	if match.NewCategoryID == transaction.CategoryID {
+		transaction.Description = match.NewDescription
+		if err := tx.Model(&transaction).Update("description", match.NewDescription).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.JournalEntry{}).Where("transaction_id = ?", transaction.ID).
+			Update("description", match.NewDescription).Error; err != nil {
+			return err
+		}
+		return learnTransaction(tx, transaction, 1)
+	}
+
+	var category models.Category
+	if err := tx.First(&category, match.NewCategoryID).Error; err != nil {
+		return err
+	}
+	if category.Type != transaction.Type {
+		return errRuleCategoryType
+	}
+	if err := ensureCategoryActive(category); err != nil {
+		return err
+	}
+	if err := unpostTransaction(tx, transaction); err != nil {
+		return err
+	}
+	transaction.CategoryID = category.ID
+	transaction.Type = category.Type
+	transaction.Description = match.NewDescription
+	if err := tx.Model(&transaction).Select("CategoryID", "Type", "Description").Updates(&transaction).Error; err != nil {
+		return err
+	}
+	if err := postTransaction(tx, transaction); err != nil {
+		return err
+	}
+	return learnTransaction(tx, transaction, 1)
+}
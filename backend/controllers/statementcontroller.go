@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"dompet/backend/utils"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Status tagihan kartu kredit
+const (
+	StatementNoPaymentDue = "no_payment_due"
+	StatementPaid         = "paid"
+	StatementPaidLate     = "paid_late"
+	StatementMinimumPaid  = "minimum_paid"
+	StatementUnpaid       = "unpaid"
+	StatementOverdue      = "overdue"
+)
+
+type CardStatement struct {
+	utils.StatementPeriod
+	StatementBalance float64 `json:"statement_balance"`
+	NewCharges       float64 `json:"new_charges"`
+	Payments         float64 `json:"payments"`
+	LatePayments     float64 `json:"late_payments"`
+	MinimumPayment   float64 `json:"minimum_payment"`
+	Remaining        float64 `json:"remaining"`
+	Status           string  `json:"status"`
+}
+
+type CurrentCycle struct {
+	utils.StatementPeriod
+	Charges float64 `json:"charges"`
+}
+
+// cardPosting adalah posting pada akun kartu kredit beserta tanggal dan jenis jurnalnya
+type cardPosting struct {
+	EntryDate time.Time
+	Kind      string
+	Debit     float64
+	Credit    float64
+}
+
+// userToday mengembalikan tanggal hari ini di zona waktu user
+func userToday(user models.User) time.Time {
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return utils.DateOf(time.Now().In(loc))
+}
+
+// buildStatement menghitung tagihan satu siklus dari posting akun kartu.
+// Transfer masuk ke kartu setelah tanggal cetak sampai jatuh tempo dihitung
+// sebagai pembayaran tagihan tersebut. Transfer setelah jatuh tempo dihitung
+// sebagai pembayaran terlambat: karena saldo tagihan sudah termasuk tunggakan
+// siklus sebelumnya, pembayaran itu melunasi tagihan yang paling lama dulu.
+func buildStatement(period utils.StatementPeriod, postings []cardPosting, minimumFloor float64, today time.Time) CardStatement {
+	statement := CardStatement{StatementPeriod: period}
+
+	var balance float64
+	for _, p := range postings {
+		date := utils.DateOf(p.EntryDate)
+		if !date.After(period.Closing) {
+			balance += p.Debit - p.Credit
+		}
+		if !date.Before(period.Start) && !date.After(period.Closing) {
+			statement.NewCharges += p.Credit
+		}
+		if p.Kind == models.JournalTransfer && date.After(period.Closing) {
+			if date.After(period.Due) {
+				statement.LatePayments += p.Debit
+			} else {
+				statement.Payments += p.Debit
+			}
+		}
+	}
+
+	// Saldo kartu negatif berarti terutang
+	statement.StatementBalance = -balance
+	statement.MinimumPayment = utils.MinimumPayment(statement.StatementBalance, minimumFloor)
+	statement.Remaining = statement.StatementBalance - statement.Payments
+	if statement.Remaining < 0 {
+		statement.Remaining = 0
+	}
+	// Hanya bagian yang dibutuhkan untuk melunasi sisa tagihan yang dihitung
+	// terlambat; kelebihannya membayar siklus berikutnya
+	statement.LatePayments = math.Min(statement.LatePayments, statement.Remaining)
+	paidOnTime := toCents(statement.Remaining) == 0
+	statement.Remaining -= statement.LatePayments
+
+	switch {
+	case toCents(statement.StatementBalance) <= 0:
+		statement.Status = StatementNoPaymentDue
+	case paidOnTime:
+		statement.Status = StatementPaid
+	case toCents(statement.Remaining) == 0:
+		statement.Status = StatementPaidLate
+	case toCents(statement.Payments) >= toCents(statement.MinimumPayment):
+		statement.Status = StatementMinimumPaid
+	case today.After(period.Due):
+		statement.Status = StatementOverdue
+	default:
+		statement.Status = StatementUnpaid
+	}
+	return statement
+}
+
+// GetWalletStatements: Menghitung tagihan per siklus untuk dompet kartu kredit
+func GetWalletStatements(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	if wallet.Type != models.WalletCreditCard || wallet.StatementDay == nil || wallet.DueDay == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statements are only available for credit card wallets with a statement day and due day"})
+		return
+	}
+
+	count := 6
+	if raw := c.Query("count"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 24 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "count must be between 1 and 24"})
+			return
+		}
+		count = parsed
+	}
+
+	account, err := walletAccount(db, wallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load wallet ledger"})
+		return
+	}
+
+	var postings []cardPosting
+	db.Table("postings").
+		Select("journal_entries.entry_date, journal_entries.kind, postings.debit, postings.credit").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("postings.account_id = ?", account.ID).
+		Order("journal_entries.entry_date").
+		Scan(&postings)
+
+	minimumFloor := utils.DefaultMinimumPaymentFloor(wallet.Currency)
+	if wallet.MinimumPaymentFloor != nil {
+		minimumFloor = *wallet.MinimumPaymentFloor
+	}
+
+	today := userToday(currentUser)
+	periods := utils.StatementPeriods(*wallet.StatementDay, *wallet.DueDay, today, count)
+	statements := make([]CardStatement, 0, len(periods))
+	for _, period := range periods {
+		statements = append(statements, buildStatement(period, postings, minimumFloor, today))
+	}
+
+	current := CurrentCycle{StatementPeriod: utils.CurrentPeriod(*wallet.StatementDay, *wallet.DueDay, today)}
+	for _, p := range postings {
+		if !utils.DateOf(p.EntryDate).Before(current.Start) {
+			current.Charges += p.Credit
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": statements, "current_cycle": current})
+}
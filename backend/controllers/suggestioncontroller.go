@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"dompet/backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSuggestions adalah jumlah maksimal saran kategori yang dikembalikan
+const maxSuggestions = 5
+
+// transactionTokens mengembalikan fitur model saran untuk satu transaksi
+func transactionTokens(description string, amount float64) []string {
+	return append(utils.Tokenize(description), utils.AmountToken(amount))
+}
+
+// ensureCategoryModel melatih model saran dari seluruh riwayat transaksi user
+// bila user belum punya statistik sama sekali
+func ensureCategoryModel(tx *gorm.DB, userID uint) error {
+	var stats int64
+	if err := tx.Model(&models.CategoryDocStat{}).Where("user_id = ?", userID).Count(&stats).Error; err != nil {
+		return err
+	}
+	if stats > 0 {
+		return nil
+	}
+
+	var transactions []models.Transaction
+	if err := tx.Where("user_id = ? AND refund_of_id IS NULL", userID).Find(&transactions).Error; err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		if err := updateCategoryStats(tx, transaction, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// learnTransaction memperbarui model saran secara bertahap: delta 1 saat
+// transaksi ditambahkan dan -1 saat dihapus. Harus dipanggil sebelum
+// transaksi diubah di database, karena pelatihan awal membaca riwayat yang ada.
+// Refund tidak pernah dipelajari, baik saat dibuat, dihapus maupun dipulihkan.
+func learnTransaction(tx *gorm.DB, transaction models.Transaction, delta int) error {
+	if transaction.RefundOfID != nil {
+		return nil
+	}
+	if err := ensureCategoryModel(tx, transaction.UserID); err != nil {
+		return err
+	}
+	return updateCategoryStats(tx, transaction, delta)
+}
+
+// updateCategoryStats menambahkan delta ke statistik kategori transaksi
+func updateCategoryStats(tx *gorm.DB, transaction models.Transaction, delta int) error {
+	if delta < 0 {
+		if err := tx.Model(&models.CategoryDocStat{}).
+			Where("user_id = ? AND category_id = ?", transaction.UserID, transaction.CategoryID).
+			Update("count", gorm.Expr("GREATEST(count + ?, 0)", delta)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.CategoryTokenStat{}).
+			Where("user_id = ? AND category_id = ? AND token IN ?", transaction.UserID, transaction.CategoryID, transactionTokens(transaction.Description, transaction.Amount)).
+			Update("count", gorm.Expr("GREATEST(count + ?, 0)", delta)).Error
+	}
+
+	doc := models.CategoryDocStat{UserID: transaction.UserID, CategoryID: transaction.CategoryID, Count: delta}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("category_doc_stats.count + ?", delta)}),
+	}).Create(&doc).Error; err != nil {
+		return err
+	}
+
+	for _, token := range transactionTokens(transaction.Description, transaction.Amount) {
+		stat := models.CategoryTokenStat{UserID: transaction.UserID, CategoryID: transaction.CategoryID, Token: token, Count: delta}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "category_id"}, {Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("category_token_stats.count + ?", delta)}),
+		}).Create(&stat).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type CategorySuggestion struct {
+	Category   models.Category `json:"category"`
+	Confidence float64         `json:"confidence"`
+}
+
+// SuggestCategory: Memberi saran kategori untuk deskripsi (dan nominal) dari
+// model naive Bayes yang dilatih dari riwayat transaksi user sendiri. Hanya
+// kategori aktif di household aktif yang disarankan.
+func SuggestCategory(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	description := c.Query("description")
+	if description == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "description is required"})
+		return
+	}
+	tokens := utils.Tokenize(description)
+	if raw := c.Query("amount"); raw != "" {
+		amount, err := strconv.ParseFloat(raw, 64)
+		if err != nil || amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be a positive number"})
+			return
+		}
+		tokens = append(tokens, utils.AmountToken(amount))
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error { return ensureCategoryModel(tx, currentUser.ID) }); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to train suggestion model"})
+		return
+	}
+
+	var categories []models.Category
+	db.Scopes(tenantScope(c)).Where("archived_at IS NULL").Find(&categories)
+	byID := make(map[uint]models.Category, len(categories))
+	categoryIDs := make([]uint, 0, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+		categoryIDs = append(categoryIDs, category.ID)
+	}
+
+	var docs []models.CategoryDocStat
+	var tokenStats []models.CategoryTokenStat
+	db.Where("user_id = ? AND category_id IN ?", currentUser.ID, categoryIDs).Find(&docs)
+	db.Where("user_id = ? AND category_id IN ?", currentUser.ID, categoryIDs).Find(&tokenStats)
+
+	model := utils.BayesModel{DocCounts: map[uint]int{}, TokenCounts: map[uint]map[string]int{}}
+	for _, doc := range docs {
+		model.DocCounts[doc.CategoryID] = doc.Count
+	}
+	for _, stat := range tokenStats {
+		if model.TokenCounts[stat.CategoryID] == nil {
+			model.TokenCounts[stat.CategoryID] = map[string]int{}
+		}
+		model.TokenCounts[stat.CategoryID][stat.Token] = stat.Count
+	}
+
+	suggestions := []CategorySuggestion{}
+	for _, ranked := range model.Rank(tokens) {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, CategorySuggestion{Category: byID[ranked.CategoryID], Confidence: ranked.Confidence})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
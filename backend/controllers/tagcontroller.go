@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TagInput struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Color string `json:"color"`
+}
+
+var (
+	tagNamePattern = regexp.MustCompile(`^[\p{L}0-9]+([-_][\p{L}0-9]+)*$`)
+	errInvalidTag  = errors.New("tag names may only contain letters, numbers, '-' and '_' (max 100 characters)")
+)
+
+// normalizeTagName menyeragamkan nama tag: huruf kecil dan spasi menjadi "-",
+// sehingga "Liburan Bali 2026" dan "liburan-bali-2026" adalah tag yang sama
+func normalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// validateTagInput menormalkan dan memvalidasi input tag
+func validateTagInput(input *TagInput) error {
+	input.Name = normalizeTagName(input.Name)
+	if !tagNamePattern.MatchString(input.Name) || len(input.Name) > 100 {
+		return errInvalidTag
+	}
+	if input.Color != "" && !categoryColorPattern.MatchString(input.Color) {
+		return errors.New("color must be a hex colour such as \"#FF8800\"")
+	}
+	input.Color = strings.ToUpper(input.Color)
+	return nil
+}
+
+// resolveTags mencari tag berdasarkan nama di household, dan membuat tag yang
+// belum ada
+func resolveTags(tx *gorm.DB, householdID, userID uint, names []string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	seen := map[string]bool{}
+	for _, raw := range names {
+		name := normalizeTagName(raw)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if !tagNamePattern.MatchString(name) || len(name) > 100 {
+			return nil, errInvalidTag
+		}
+
+		tag := models.Tag{HouseholdID: householdID, Name: name}
+		if err := tx.Where(models.Tag{HouseholdID: householdID, Name: name}).
+			Attrs(models.Tag{UserID: userID}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+type TagWithUsage struct {
+	models.Tag
+	TransactionCount int64 `json:"transaction_count"`
+}
+
+// GetAllTags: Mendapatkan semua tag household aktif beserta jumlah transaksinya
+func GetAllTags(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var tags []TagWithUsage
+	db.Model(&models.Tag{}).Scopes(tenantScope(c)).
+		Select("tags.*, (SELECT COUNT(*) FROM transaction_tags JOIN transactions ON transactions.id = transaction_tags.transaction_id WHERE transaction_tags.tag_id = tags.id AND transactions.deleted_at IS NULL) AS transaction_count").
+		Order("name").Scan(&tags)
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// CreateTag: Membuat tag baru di household aktif
+func CreateTag(c *gin.Context) {
+	var input TagInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateTagInput(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing int64
+	db.Model(&models.Tag{}).Scopes(tenantScope(c)).Where("name = ?", input.Name).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A tag with this name already exists"})
+		return
+	}
+
+	tag := models.Tag{UserID: currentUser.ID, HouseholdID: getCurrentHousehold(c).ID, Name: input.Name, Color: input.Color}
+	if err := db.Create(&tag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// UpdateTag: Mengganti nama atau warna tag
+func UpdateTag(c *gin.Context) {
+	var input TagInput
+	var tag models.Tag
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&tag).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateTagInput(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing int64
+	db.Model(&models.Tag{}).Scopes(tenantScope(c)).Where("name = ? AND id <> ?", input.Name, tag.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A tag with this name already exists"})
+		return
+	}
+
+	db.Model(&tag).Updates(map[string]interface{}{"name": input.Name, "color": input.Color})
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// DeleteTag: Menghapus tag. Transaksinya tidak ikut terhapus, hanya lepas dari tag.
+func DeleteTag(c *gin.Context) {
+	var tag models.Tag
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := db.Scopes(tenantScope(c)).Where("id = ?", c.Param("id")).First(&tag).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TransactionRule{}).Where("add_tag_id = ?", tag.ID).Update("add_tag_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SavingsGoal{}).Where("tag_id = ?", tag.ID).Update("tag_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Tag deleted successfully"})
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// getCurrentHousehold mengembalikan household aktif yang dipilih oleh
+// HouseholdMiddleware
+func getCurrentHousehold(c *gin.Context) models.Household {
+	household, _ := c.Get("currentHousehold")
+	h, _ := household.(models.Household)
+	return h
+}
+
+// householdScope membatasi query ke household tertentu. Kolom diberi nama
+// tabel query agar aman dipakai bersama Joins.
+func householdScope(householdID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "household_id"},
+			Value:  householdID,
+		})
+	}
+}
+
+// tenantScope membatasi query ke household aktif. Semua query data milik
+// workspace harus memakai db.Scopes(tenantScope(c)).
+func tenantScope(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return householdScope(getCurrentHousehold(c).ID)
+}
+
+// requireHouseholdRole memastikan user punya minimal peran minRole pada
+// household aktif, dan menulis respons 403 bila tidak
+func requireHouseholdRole(c *gin.Context, minRole string) bool {
+	if roleRank[getCurrentHousehold(c).Role] >= roleRank[minRole] {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to modify data in this household"})
+	return false
+}
@@ -2,22 +2,112 @@ package controllers
 
 import (
 	"dompet/backend/models"
+	"dompet/backend/utils"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionInput struct {
 	WalletID        uint      `json:"wallet_id" binding:"required"`
-	CategoryID      uint      `json:"category_id" binding:"required"`
+	CategoryID      uint      `json:"category_id"` // Boleh kosong bila aturan kategorisasi menentukannya
 	Amount          float64   `json:"amount" binding:"required,gt=0"`
 	Description     string    `json:"description"`
 	TransactionDate time.Time `json:"transaction_date" binding:"required"`
+	// Nama tag; tag yang belum ada dibuat otomatis. Saat update, nil berarti
+	// tag tidak diubah dan [] menghapus semua tag.
+	Tags []string `json:"tags"`
+	// Rincian per kategori. Bila diisi, jumlahnya harus sama dengan amount
+	// dan category_id diabaikan. Saat update, nil berarti rincian tidak
+	// diubah dan [] menghapus rincian.
+	Splits []SplitInput `json:"splits" binding:"omitempty,dive"`
+	// Status awal pending atau cleared (default cleared). Status reconciled
+	// hanya bisa diberikan lewat rekonsiliasi.
+	Status string `json:"status" binding:"omitempty,oneof=pending cleared"`
+	// Hutang/piutang yang dicicil lewat transaksi ini. Saat update, nil
+	// berarti tautan tidak diubah dan 0 melepas tautan.
+	DebtID *uint `json:"debt_id"`
 }
 
-// CreateTransaction: Membuat transaksi baru dan memperbarui saldo dompet
+type SplitInput struct {
+	CategoryID  uint    `json:"category_id" binding:"required"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Description string  `json:"description"`
+}
+
+// errCategoryOutsideHousehold dikembalikan bila kategori tidak ada di
+// household dompet. Transaksi dibukukan di buku besar household dompetnya,
+// sehingga anggota dompet bersama harus memakai kategori household tersebut,
+// bukan kategori household pribadinya.
+var errCategoryOutsideHousehold = errors.New("category not found in the wallet's household; transactions on a shared wallet must use that household's categories")
+
+// householdCategory memuat kategori yang harus berasal dari household dompet
+func householdCategory(tx *gorm.DB, householdID, categoryID uint) (models.Category, error) {
+	var category models.Category
+	err := tx.Scopes(householdScope(householdID)).First(&category, categoryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return category, fmt.Errorf("%w (category %d)", errCategoryOutsideHousehold, categoryID)
+	}
+	return category, err
+}
+
+// errInvalidSplit dikembalikan bila rincian transaksi tidak valid
+var errInvalidSplit = errors.New("invalid split")
+
+// buildSplits memvalidasi rincian transaksi: semua kategori harus aktif di
+// household dompet dan bertipe sama, dan jumlahnya sama dengan nominal
+// transaksi. Kategori baris pertama menjadi kategori utama transaksi.
+func buildSplits(tx *gorm.DB, householdID uint, amount float64, inputs []SplitInput) ([]models.TransactionSplit, models.Category, error) {
+	var primary models.Category
+	splits := make([]models.TransactionSplit, 0, len(inputs))
+	var total int64
+	for i, input := range inputs {
+		category, err := householdCategory(tx, householdID, input.CategoryID)
+		if err != nil {
+			return nil, primary, err
+		}
+		if err := ensureCategoryActive(category); err != nil {
+			return nil, primary, err
+		}
+		if i == 0 {
+			primary = category
+		} else if category.Type != primary.Type {
+			return nil, primary, fmt.Errorf("%w: all split categories must have the same type", errInvalidSplit)
+		}
+		total += toCents(input.Amount)
+		splits = append(splits, models.TransactionSplit{CategoryID: category.ID, Amount: input.Amount, Description: input.Description})
+	}
+	if total != toCents(amount) {
+		return nil, primary, fmt.Errorf("%w: split amounts must add up to the transaction amount", errInvalidSplit)
+	}
+	return splits, primary, nil
+}
+
+// errTransactionReconciled dikembalikan bila transaksi yang sudah
+// direkonsiliasi diubah tanpa dibuka kuncinya
+var errTransactionReconciled = errors.New("transaction is reconciled; unlock it before editing")
+
+// ensureNotReconciled menolak perubahan pada transaksi yang sudah dikunci
+func ensureNotReconciled(transaction models.Transaction) error {
+	if transaction.Status == models.TransactionReconciled {
+		return errTransactionReconciled
+	}
+	return nil
+}
+
+// errCategoryRequired dikembalikan bila kategori tidak dikirim dan tidak ada
+// aturan yang menentukannya
+var errCategoryRequired = errors.New("category_id is required when no rule matches the transaction")
+
+// CreateTransaction: Membuat transaksi baru dan memperbarui saldo dompet.
+// Aturan kategorisasi household dijalankan dulu: aturan bisa mengisi kategori
+// (bila tidak dikirim) dan mengganti deskripsi. Transaksi lain yang mirip
+// dikembalikan di possible_duplicates.
 func CreateTransaction(c *gin.Context) {
 	var input TransactionInput
 	db := c.MustGet("db").(*gorm.DB)
@@ -28,66 +118,681 @@ func CreateTransaction(c *gin.Context) {
 		return
 	}
 
+	var transaction models.Transaction
+
 	// Mulai database transaction
 	err := db.Transaction(func(tx *gorm.DB) error {
-		// 1. Dapatkan dompet dan kategori, pastikan milik user yang benar
-		var wallet models.Wallet
-		if err := tx.Where("id = ? AND user_id = ?", input.WalletID, currentUser.ID).First(&wallet).Error; err != nil {
-			return err // Dompet tidak ditemukan atau bukan milik user
+		// 1. Dapatkan dompet dan kategori, pastikan user boleh mencatat di dompet itu
+		wallet, err := findWalletWithRole(tx, input.WalletID, currentUser.ID, models.RoleEditor)
+		if err != nil {
+			return err // Dompet tidak ditemukan atau user hanya viewer
 		}
+		if err := ensureWalletActive(wallet); err != nil {
+			return err
+		}
+
+		outcome := evaluateRules(householdRules(tx, wallet.HouseholdID), input.Description, input.Amount, wallet.ID)
+		input.Description = outcome.Description
 
 		var category models.Category
-		if err := tx.Where("id = ? AND user_id = ?", input.CategoryID, currentUser.ID).First(&category).Error; err != nil {
-			return err // Kategori tidak ditemukan atau bukan milik user
+		var splits []models.TransactionSplit
+		if len(input.Splits) > 0 {
+			// Transaksi dipecah: kategori utama adalah kategori baris pertama
+			splits, category, err = buildSplits(tx, wallet.HouseholdID, input.Amount, input.Splits)
+			if err != nil {
+				return err
+			}
+			input.CategoryID = category.ID
+		} else {
+			if input.CategoryID == 0 {
+				if outcome.CategoryID == nil {
+					return errCategoryRequired
+				}
+				input.CategoryID = *outcome.CategoryID
+			}
+
+			// Kategori harus berasal dari household yang sama dengan dompetnya
+			if category, err = householdCategory(tx, wallet.HouseholdID, input.CategoryID); err != nil {
+				return err
+			}
+			if err := ensureCategoryActive(category); err != nil {
+				return err
+			}
 		}
 
 		// 2. Buat record transaksi baru
-		transaction := models.Transaction{
+		transaction = models.Transaction{
 			UserID:          currentUser.ID,
+			HouseholdID:     wallet.HouseholdID,
 			WalletID:        input.WalletID,
 			CategoryID:      input.CategoryID,
 			Amount:          input.Amount,
 			Type:            category.Type, // Tipe transaksi mengikuti tipe kategori
 			Description:     input.Description,
 			TransactionDate: input.TransactionDate,
+			Status:          models.TransactionCleared,
+			Splits:          splits,
+		}
+		if input.Status != "" {
+			transaction.Status = input.Status
+		}
+		if input.DebtID != nil && *input.DebtID != 0 {
+			if err := checkDebtLink(tx, *input.DebtID, wallet, transaction); err != nil {
+				return err
+			}
+			transaction.DebtID = input.DebtID
 		}
 
-		if err := tx.Create(&transaction).Error; err != nil {
+		// Tag dari input ditambah tag dari aturan yang cocok
+		transaction.Tags, err = resolveTags(tx, wallet.HouseholdID, currentUser.ID, input.Tags)
+		if err != nil {
 			return err
 		}
-
-		// 3. Perbarui saldo dompet
-		var newBalance float64
-		if category.Type == "income" {
-			newBalance = wallet.Balance + input.Amount
-		} else {
-			newBalance = wallet.Balance - input.Amount
+		if len(outcome.TagIDs) > 0 {
+			var ruleTags []models.Tag
+			tx.Scopes(householdScope(wallet.HouseholdID)).Where("id IN ?", outcome.TagIDs).Find(&ruleTags)
+			for _, tag := range ruleTags {
+				if !transactionHasTag(transaction, tag.ID) {
+					transaction.Tags = append(transaction.Tags, tag)
+				}
+			}
 		}
 
-		if err := tx.Model(&wallet).Update("balance", newBalance).Error; err != nil {
+		// Model saran kategori belajar dari transaksi baru
+		if err := learnTransaction(tx, transaction, 1); err != nil {
+			return err
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
 			return err
 		}
 
-		// Jika semua berhasil, kembalikan nil
-		return nil
+		// 3. Bukukan ke jurnal, sekaligus memperbarui saldo dompet
+		return postTransaction(tx, transaction)
 	})
 
+	if errors.Is(err, errWalletForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errCategoryRequired) || errors.Is(err, errCategoryOutsideHousehold) || errors.Is(err, errInvalidTag) || errors.Is(err, errInvalidSplit) || errors.Is(err, errInvalidDebt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if isWalletRuleError(err) || errors.Is(err, errDebtOverpaid) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction failed: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Transaction created successfully"})
+	// Transaksi tetap disimpan; kemungkinan duplikat hanya dilaporkan agar
+	// bisa ditinjau di /transactions/duplicates
+	response := gin.H{"data": transaction, "message": "Transaction created successfully"}
+	if duplicates, err := findDuplicates(db, transaction, duplicateWindowDays()); err == nil && len(duplicates) > 0 {
+		response["possible_duplicates"] = duplicates
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// findTransactionWithRole mencari transaksi dan memastikan user punya minimal
+// peran minRole pada dompetnya
+func findTransactionWithRole(db *gorm.DB, id interface{}, userID uint, minRole string) (models.Transaction, error) {
+	var transaction models.Transaction
+	if err := db.First(&transaction, "id = ?", id).Error; err != nil {
+		return transaction, err
+	}
+	_, err := findWalletWithRole(db, transaction.WalletID, userID, minRole)
+	return transaction, err
+}
+
+// loadTransactionWithRole seperti findTransactionWithRole, tetapi langsung
+// menulis respons 404/403 bila gagal
+func loadTransactionWithRole(c *gin.Context, db *gorm.DB, minRole string) (models.Transaction, bool) {
+	currentUser, _ := getCurrentUser(c)
+	transaction, err := findTransactionWithRole(db, c.Param("id"), currentUser.ID, minRole)
+	switch {
+	case err == nil:
+		return transaction, true
+	case errors.Is(err, errWalletForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to modify this transaction"})
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+	}
+	return transaction, false
 }
 
-// GetAllTransactions: Mendapatkan semua transaksi milik user
+// GetAllTransactions: Mendapatkan semua transaksi household aktif dan
+// transaksi pada dompet bersama yang diikuti user. Bisa difilter dengan
+// ?tag= atau ?tag_id=.
 func GetAllTransactions(c *gin.Context) {
 	var transactions []models.Transaction
 	db := c.MustGet("db").(*gorm.DB)
 	currentUser, _ := getCurrentUser(c)
 
-	// Gunakan Preload untuk memuat data relasi Wallet dan Category
-	db.Preload("Wallet").Preload("Category").Where("user_id = ?", currentUser.ID).Order("transaction_date desc").Find(&transactions)
+	// Gunakan Preload untuk memuat data relasi Wallet, Category, Tags dan pencatatnya
+	query := db.Preload("User").Preload("Wallet").Preload("Category").Preload("Tags").Preload("Splits.Category").
+		Where("household_id = ? OR wallet_id IN (?)", getCurrentHousehold(c).ID, sharedWalletIDs(db, currentUser.ID))
+
+	// ?tag=nama (boleh berulang): transaksi harus punya semua tag tersebut
+	for _, name := range c.QueryArray("tag") {
+		query = query.Where("id IN (?)", db.Table("transaction_tags").Select("transaction_tags.transaction_id").
+			Joins("JOIN tags ON tags.id = transaction_tags.tag_id").
+			Where("tags.name = ?", normalizeTagName(name)))
+	}
+	for _, tagID := range c.QueryArray("tag_id") {
+		query = query.Where("id IN (?)", db.Table("transaction_tags").Select("transaction_id").Where("tag_id = ?", tagID))
+	}
+
+	query.Order("transaction_date desc").Find(&transactions)
 
 	c.JSON(http.StatusOK, gin.H{"data": transactions})
 }
+
+// GetTransactionByID: Mendapatkan satu transaksi beserta jurnalnya
+func GetTransactionByID(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleViewer)
+	if !ok {
+		return
+	}
+	db.Preload("User").Preload("Wallet").Preload("Category").Preload("Tags").Preload("Splits.Category").First(&transaction, transaction.ID)
+
+	var entries []models.JournalEntry
+	db.Preload("Postings.Account").Where("transaction_id = ?", transaction.ID).Find(&entries)
+
+	c.Header("ETag", etagFor(transaction.Version))
+	c.JSON(http.StatusOK, gin.H{"data": transaction, "journal": entries})
+}
+
+// UpdateTransaction: Mengubah transaksi lalu membukukan ulang jurnalnya.
+// Header If-Match berisi ETag dari GET mencegah perubahan yang saling timpa.
+func UpdateTransaction(c *gin.Context) {
+	var input TransactionInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, transaction.Version) {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Tanpa category_id, kategori transaksi tidak diubah
+	if input.CategoryID == 0 {
+		input.CategoryID = transaction.CategoryID
+	}
+
+	if transaction.RefundOfID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errRefundNotEditable.Error()})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureNotReconciled(transaction); err != nil {
+			return err
+		}
+		// Menaikkan versi sekaligus mengunci baris transaksi
+		if err := bumpVersion(tx, &models.Transaction{}, transaction.ID, transaction.Version); err != nil {
+			return err
+		}
+		transaction.Version++
+		// Kunci baris seperti RefundTransaction agar refund yang bersamaan
+		// menunggu perubahan ini selesai
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Transaction{}, transaction.ID).Error; err != nil {
+			return err
+		}
+		refunded, err := refundedAmount(tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+		if refunded > 0 && (toCents(input.Amount) < toCents(refunded) || input.WalletID != transaction.WalletID) {
+			return errExpenseRefunded
+		}
+		wallet, err := findWalletWithRole(tx, input.WalletID, currentUser.ID, models.RoleEditor)
+		if err != nil {
+			return err
+		}
+		if wallet.ID != transaction.WalletID {
+			if err := ensureWalletActive(wallet); err != nil {
+				return err
+			}
+		}
+
+		// Tanpa splits, rincian lama dipertahankan dan divalidasi ulang
+		// terhadap nominal dan household yang baru
+		splitInputs := input.Splits
+		if splitInputs == nil {
+			var existing []models.TransactionSplit
+			tx.Where("transaction_id = ?", transaction.ID).Order("id").Find(&existing)
+			for _, split := range existing {
+				splitInputs = append(splitInputs, SplitInput{CategoryID: split.CategoryID, Amount: split.Amount, Description: split.Description})
+			}
+		}
+
+		var category models.Category
+		var splits []models.TransactionSplit
+		if len(splitInputs) > 0 {
+			splits, category, err = buildSplits(tx, wallet.HouseholdID, input.Amount, splitInputs)
+			if err != nil {
+				return err
+			}
+			input.CategoryID = category.ID
+		} else {
+			if category, err = householdCategory(tx, wallet.HouseholdID, input.CategoryID); err != nil {
+				return err
+			}
+			if category.ID != transaction.CategoryID {
+				if err := ensureCategoryActive(category); err != nil {
+					return err
+				}
+			}
+		}
+		if refunded > 0 && category.Type != transaction.Type {
+			return errExpenseRefunded
+		}
+
+		// Batalkan efek lama sebelum nilai transaksi diganti
+		if err := learnTransaction(tx, transaction, -1); err != nil {
+			return err
+		}
+		if err := unpostTransaction(tx, transaction); err != nil {
+			return err
+		}
+
+		previousHouseholdID := transaction.HouseholdID
+		transaction.WalletID = input.WalletID
+		transaction.HouseholdID = wallet.HouseholdID
+		transaction.CategoryID = input.CategoryID
+		transaction.Amount = input.Amount
+		transaction.Type = category.Type
+		transaction.Description = input.Description
+		transaction.TransactionDate = input.TransactionDate
+		if input.Status != "" {
+			transaction.Status = input.Status
+		}
+		if input.DebtID != nil {
+			transaction.DebtID = input.DebtID
+			if *input.DebtID == 0 {
+				transaction.DebtID = nil
+			}
+		}
+		if transaction.DebtID != nil {
+			if err := checkDebtLink(tx, *transaction.DebtID, wallet, transaction); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&transaction).Select("WalletID", "HouseholdID", "CategoryID", "Amount", "Type", "Description", "TransactionDate", "Status", "DebtID").Updates(&transaction).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+			return err
+		}
+		for i := range splits {
+			splits[i].TransactionID = transaction.ID
+		}
+		if len(splits) > 0 {
+			if err := tx.Create(&splits).Error; err != nil {
+				return err
+			}
+		}
+		transaction.Splits = splits
+		if err := learnTransaction(tx, transaction, 1); err != nil {
+			return err
+		}
+
+		// Tag milik household lama dilepas bila transaksi pindah household
+		switch {
+		case input.Tags != nil:
+			tags, err := resolveTags(tx, wallet.HouseholdID, currentUser.ID, input.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&transaction).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		case wallet.HouseholdID != previousHouseholdID:
+			if err := tx.Model(&transaction).Association("Tags").Clear(); err != nil {
+				return err
+			}
+		}
+
+		return postTransaction(tx, transaction)
+	})
+
+	if errors.Is(err, errWalletForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errCategoryOutsideHousehold) || errors.Is(err, errInvalidTag) || errors.Is(err, errInvalidSplit) || errors.Is(err, errInvalidDebt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errTransactionReconciled) || errors.Is(err, errVersionConflict) || errors.Is(err, errExpenseRefunded) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if isWalletRuleError(err) || errors.Is(err, errDebtOverpaid) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transaction failed: " + err.Error()})
+		return
+	}
+	c.Header("ETag", etagFor(transaction.Version))
+
+	c.JSON(http.StatusOK, gin.H{"data": transaction})
+}
+
+// removeTransaction memindahkan transaksi ke tempat sampah: jurnalnya
+// dibatalkan sehingga saldo dompet kembali seperti sebelum transaksi dicatat.
+// Tag, rincian split dan lampiran tetap disimpan untuk restoreTransaction dan
+// baru dihapus oleh purgeTransaction. Pengeluaran yang masih punya refund
+// aktif tidak bisa dihapus.
+func removeTransaction(tx *gorm.DB, transaction models.Transaction) error {
+	if transaction.RefundOfID == nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Transaction{}, transaction.ID).Error; err != nil {
+			return err
+		}
+		refunded, err := refundedAmount(tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+		if refunded > 0 {
+			return errExpenseHasRefunds
+		}
+	}
+	if err := learnTransaction(tx, transaction, -1); err != nil {
+		return err
+	}
+	if err := unpostTransaction(tx, transaction); err != nil {
+		return err
+	}
+	return tx.Delete(&transaction).Error
+}
+
+// DeleteTransaction: Memindahkan transaksi ke tempat sampah dan mengembalikan
+// saldo dompet
+func DeleteTransaction(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	if err := ensureNotReconciled(transaction); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return removeTransaction(tx, transaction)
+	})
+
+	if errors.Is(err, errExpenseHasRefunds) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transaction: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Transaction moved to trash"})
+}
+
+type TransactionStatusInput struct {
+	Status string `json:"status" binding:"required,oneof=pending cleared"`
+}
+
+// UpdateTransactionStatus: Menandai transaksi pending atau cleared tanpa
+// mengubah data lainnya
+func UpdateTransactionStatus(c *gin.Context) {
+	var input TransactionStatusInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkIfMatch(c, transaction.Version) {
+		return
+	}
+	if err := ensureNotReconciled(transaction); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := db.Model(&transaction).Where("version = ?", transaction.Version).
+		Updates(map[string]interface{}{"status": input.Status, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": errVersionConflict.Error()})
+		return
+	}
+	transaction.Status = input.Status
+	transaction.Version++
+
+	c.Header("ETag", etagFor(transaction.Version))
+	c.JSON(http.StatusOK, gin.H{"data": transaction})
+}
+
+// UnlockTransaction: Membuka kunci transaksi yang sudah direkonsiliasi agar
+// bisa diubah lagi (hanya owner dompet). Statusnya kembali menjadi cleared.
+func UnlockTransaction(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	transaction, ok := loadTransactionWithRole(c, db, models.RoleOwner)
+	if !ok {
+		return
+	}
+	if !checkIfMatch(c, transaction.Version) {
+		return
+	}
+	if transaction.Status != models.TransactionReconciled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transaction is not reconciled"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, &models.Transaction{}, transaction.ID, transaction.Version); err != nil {
+			return err
+		}
+		return tx.Model(&transaction).Updates(map[string]interface{}{"status": models.TransactionCleared, "reconciliation_id": nil}).Error
+	})
+	if errors.Is(err, errVersionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock transaction"})
+		return
+	}
+	transaction.Status = models.TransactionCleared
+	transaction.ReconciliationID = nil
+	transaction.Version++
+
+	c.Header("ETag", etagFor(transaction.Version))
+	c.JSON(http.StatusOK, gin.H{"data": transaction, "message": "Transaction unlocked"})
+}
+
+// Kesalahan refund yang ditampilkan ke klien
+var (
+	errRefundNotExpense  = errors.New("only expense transactions can be refunded")
+	errRefundExceeded    = errors.New("refund exceeds the amount of the expense that has not been refunded yet")
+	errRefundWallet      = errors.New("refund wallet must be in the same household and currency as the expense")
+	errRefundNotEditable = errors.New("refunds cannot be edited; delete the refund and record it again")
+	errExpenseRefunded   = errors.New("expense has refunds; its amount cannot go below the refunded total and its type and wallet cannot change")
+	errExpenseHasRefunds = errors.New("expense has refunds; delete its refunds first")
+	errRefundOrphaned    = errors.New("the refunded expense is in the trash or no longer has room for this refund; restore or edit it first")
+)
+
+// refundedAmount menjumlahkan refund aktif atas sebuah pengeluaran
+func refundedAmount(tx *gorm.DB, transactionID uint) (float64, error) {
+	var refunded float64
+	err := tx.Model(&models.Transaction{}).Where("refund_of_id = ?", transactionID).
+		Select("COALESCE(SUM(amount), 0)").Scan(&refunded).Error
+	return refunded, err
+}
+
+type RefundInput struct {
+	Amount          float64   `json:"amount" binding:"required,gt=0"`
+	WalletID        uint      `json:"wallet_id"` // Kosong berarti dompet pengeluaran asal
+	Description     string    `json:"description"`
+	TransactionDate time.Time `json:"transaction_date"`
+}
+
+// refundSplits membagi nominal refund ke rincian pengeluaran asal secara
+// proporsional, sehingga setiap kategori dikembalikan sesuai porsinya
+func refundSplits(tx *gorm.DB, original models.Transaction, amount float64) ([]models.TransactionSplit, error) {
+	var existing []models.TransactionSplit
+	if err := tx.Where("transaction_id = ?", original.ID).Order("id").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+	weights := make([]float64, len(existing))
+	for i, split := range existing {
+		weights[i] = split.Amount
+	}
+	shares := utils.SplitByWeight(toCents(amount), weights)
+	splits := make([]models.TransactionSplit, 0, len(existing))
+	for i, split := range existing {
+		if shares[i] == 0 {
+			continue
+		}
+		splits = append(splits, models.TransactionSplit{CategoryID: split.CategoryID, Amount: float64(shares[i]) / 100, Description: split.Description})
+	}
+	return splits, nil
+}
+
+// RefundTransaction: Mencatat pengembalian dana (refund) atas pengeluaran.
+// Refund menambah saldo dompet dan mengurangi beban kategori pengeluaran
+// asalnya, bukan dicatat sebagai pemasukan. Total refund tidak boleh melebihi
+// nominal pengeluarannya.
+func RefundTransaction(c *gin.Context) {
+	var input RefundInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	original, ok := loadTransactionWithRole(c, db, models.RoleEditor)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.WalletID == 0 {
+		input.WalletID = original.WalletID
+	}
+	if input.Description == "" {
+		input.Description = "Refund: " + original.Description
+	}
+	if input.TransactionDate.IsZero() {
+		input.TransactionDate = userToday(currentUser)
+	}
+
+	var refund models.Transaction
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Kunci pengeluaran asal agar refund yang bersamaan tidak melebihi nominalnya
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&original, original.ID).Error; err != nil {
+			return err
+		}
+		if original.Type != "expense" {
+			return errRefundNotExpense
+		}
+
+		wallet, err := findWalletWithRole(tx, input.WalletID, currentUser.ID, models.RoleEditor)
+		if err != nil {
+			return err
+		}
+		if err := ensureWalletActive(wallet); err != nil {
+			return err
+		}
+		var originalWallet models.Wallet
+		if err := tx.Unscoped().First(&originalWallet, original.WalletID).Error; err != nil {
+			return err
+		}
+		if wallet.HouseholdID != original.HouseholdID || wallet.Currency != originalWallet.Currency {
+			return errRefundWallet
+		}
+
+		var category models.Category
+		if err := tx.First(&category, original.CategoryID).Error; err != nil {
+			return err
+		}
+		if err := ensureCategoryActive(category); err != nil {
+			return err
+		}
+
+		refunded, err := refundedAmount(tx, original.ID)
+		if err != nil {
+			return err
+		}
+		if toCents(refunded)+toCents(input.Amount) > toCents(original.Amount) {
+			return errRefundExceeded
+		}
+
+		splits, err := refundSplits(tx, original, input.Amount)
+		if err != nil {
+			return err
+		}
+		refund = models.Transaction{
+			UserID:          currentUser.ID,
+			HouseholdID:     wallet.HouseholdID,
+			WalletID:        wallet.ID,
+			CategoryID:      original.CategoryID,
+			Amount:          input.Amount,
+			Type:            "refund",
+			Description:     input.Description,
+			TransactionDate: input.TransactionDate,
+			Status:          models.TransactionCleared,
+			RefundOfID:      &original.ID,
+			Splits:          splits,
+		}
+		if err := tx.Create(&refund).Error; err != nil {
+			return err
+		}
+		return postTransaction(tx, refund)
+	})
+
+	if errors.Is(err, errWalletForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errRefundNotExpense) || errors.Is(err, errRefundWallet) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if isWalletRuleError(err) || errors.Is(err, errRefundExceeded) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Refund failed: " + err.Error()})
+		return
+	}
+
+	db.Preload("Wallet").Preload("Category").Preload("Splits.Category").First(&refund, refund.ID)
+	c.JSON(http.StatusOK, gin.H{"data": refund, "message": "Refund recorded successfully"})
+}
@@ -0,0 +1,460 @@
+package controllers
+
+import (
+	"context"
+	"dompet/backend/models"
+	"dompet/backend/storage"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultTrashRetention adalah lama data disimpan di tempat sampah (hari)
+// bila TRASH_RETENTION_DAYS tidak diatur
+const defaultTrashRetention = 30
+
+var (
+	errWalletInTrash   = errors.New("wallet is in the trash; restore it first")
+	errCategoryInTrash = errors.New("category is in the trash; restore it first")
+)
+
+// trashRetentionDays membaca lama penyimpanan tempat sampah dari
+// TRASH_RETENTION_DAYS
+func trashRetentionDays() int {
+	if days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return defaultTrashRetention
+}
+
+// TrashContents adalah isi tempat sampah household aktif
+type TrashContents struct {
+	Wallets      []models.Wallet      `json:"wallets"`
+	Categories   []models.Category    `json:"categories"`
+	Transactions []models.Transaction `json:"transactions"`
+}
+
+// GetTrash: Daftar dompet, kategori dan transaksi household aktif yang ada di
+// tempat sampah. Transaksi yang terhapus bersama dompetnya tidak ditampilkan
+// terpisah karena ikut pulih saat dompetnya dipulihkan.
+func GetTrash(c *gin.Context) {
+	var trash TrashContents
+	db := c.MustGet("db").(*gorm.DB)
+
+	db.Unscoped().Scopes(tenantScope(c)).Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&trash.Wallets)
+	db.Unscoped().Scopes(tenantScope(c)).Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&trash.Categories)
+	db.Unscoped().Preload("Wallet").Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Scopes(tenantScope(c)).
+		Where("deleted_at IS NOT NULL AND wallet_id IN (?)", db.Model(&models.Wallet{}).Select("id")).
+		Order("deleted_at DESC").Find(&trash.Transactions)
+
+	c.JSON(http.StatusOK, gin.H{"data": trash, "retention_days": trashRetentionDays()})
+}
+
+// restoreTransaction memulihkan transaksi dari tempat sampah dan membukukan
+// ulang jurnalnya. Dompet dan semua kategorinya harus sudah aktif.
+func restoreTransaction(tx *gorm.DB, transaction models.Transaction) error {
+	var wallet models.Wallet
+	if err := tx.First(&wallet, transaction.WalletID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return errWalletInTrash
+	} else if err != nil {
+		return err
+	}
+
+	var category models.Category
+	if err := tx.First(&category, transaction.CategoryID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return errCategoryInTrash
+	} else if err != nil {
+		return err
+	}
+	var splitCategoryIDs []uint
+	if err := tx.Model(&models.TransactionSplit{}).Where("transaction_id = ?", transaction.ID).
+		Distinct().Pluck("category_id", &splitCategoryIDs).Error; err != nil {
+		return err
+	}
+	if len(splitCategoryIDs) > 0 {
+		var active int64
+		tx.Model(&models.Category{}).Where("id IN ?", splitCategoryIDs).Count(&active)
+		if int(active) != len(splitCategoryIDs) {
+			return errCategoryInTrash
+		}
+	}
+
+	// Tipe kategori bisa sudah berubah selama transaksi ada di tempat sampah;
+	// refund tetap refund, dan hanya bisa pulih bila pengeluaran asalnya aktif
+	// dan masih cukup untuk menampungnya
+	if transaction.RefundOfID == nil {
+		transaction.Type = category.Type
+	} else {
+		var original models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&original, *transaction.RefundOfID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRefundOrphaned
+		} else if err != nil {
+			return err
+		}
+		refunded, err := refundedAmount(tx, original.ID)
+		if err != nil {
+			return err
+		}
+		if original.Type != "expense" || toCents(refunded)+toCents(transaction.Amount) > toCents(original.Amount) {
+			return errRefundOrphaned
+		}
+	}
+	// Cicilan lain bisa sudah melunasi utang-piutangnya selama transaksi ada
+	// di tempat sampah
+	if transaction.DebtID != nil {
+		if err := checkDebtLink(tx, *transaction.DebtID, wallet, transaction); err != nil {
+			return err
+		}
+	}
+	if err := learnTransaction(tx, transaction, 1); err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Model(&transaction).
+		Updates(map[string]interface{}{"deleted_at": nil, "type": transaction.Type, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return err
+	}
+	transaction.DeletedAt = gorm.DeletedAt{}
+	transaction.Version++
+	return postTransaction(tx, transaction)
+}
+
+// restoreWallet memulihkan dompet beserta jurnal dan transaksi yang terhapus
+// bersamanya
+func restoreWallet(tx *gorm.DB, wallet models.Wallet) error {
+	deletedAt := wallet.DeletedAt.Time
+	if err := tx.Unscoped().Model(&wallet).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return err
+	}
+
+	account, err := walletAccount(tx, wallet)
+	if err != nil {
+		return err
+	}
+	var entries []models.JournalEntry
+	if err := tx.Unscoped().Where("deleted_at = ? AND voided_postings IS NOT NULL", deletedAt).
+		Order("entry_date, id").Find(&entries).Error; err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		accounts, err := voidedAccounts(entry)
+		if err != nil {
+			return err
+		}
+		if !containsID(accounts, account.ID) {
+			continue
+		}
+		if err := restoreJournal(tx, entry); err != nil {
+			return err
+		}
+	}
+
+	var transactions []models.Transaction
+	if err := tx.Unscoped().Where("wallet_id = ? AND deleted_at = ?", wallet.ID, deletedAt).
+		Order("refund_of_id IS NOT NULL, transaction_date, id").Find(&transactions).Error; err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		if err := restoreTransaction(tx, transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRestoreError menulis respons untuk kegagalan pemulihan
+func writeRestoreError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errWalletInTrash) || errors.Is(err, errCategoryInTrash) || errors.Is(err, errRefundOrphaned) || errors.Is(err, errInvalidDebt):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// Dompet lain yang terlibat transfer sudah dihapus
+		c.JSON(http.StatusConflict, gin.H{"error": "A related wallet or category is in the trash; restore it first"})
+	case isWalletRuleError(err) || errors.Is(err, errDebtOverpaid):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore: " + err.Error()})
+	}
+}
+
+// RestoreTransaction: Memulihkan transaksi dari tempat sampah dan
+// mengembalikan efeknya ke saldo dompet
+func RestoreTransaction(c *gin.Context) {
+	var transaction models.Transaction
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&transaction).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found in trash"})
+		return
+	}
+	if _, err := findWalletWithRole(db, transaction.WalletID, currentUser.ID, models.RoleEditor); err != nil {
+		if errors.Is(err, errWalletForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to modify this transaction"})
+			return
+		}
+		// Dompetnya juga ada di tempat sampah
+		if transaction.HouseholdID != getCurrentHousehold(c).ID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found in trash"})
+			return
+		}
+		writeRestoreError(c, errWalletInTrash)
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return restoreTransaction(tx, transaction)
+	})
+	if err != nil {
+		writeRestoreError(c, err)
+		return
+	}
+
+	db.Preload("User").Preload("Wallet").Preload("Category").Preload("Tags").Preload("Splits.Category").First(&transaction, transaction.ID)
+	c.JSON(http.StatusOK, gin.H{"data": transaction, "message": "Transaction restored"})
+}
+
+// RestoreWallet: Memulihkan dompet dari tempat sampah (hanya owner), beserta
+// transaksi dan transfer yang terhapus bersamanya
+func RestoreWallet(c *gin.Context) {
+	var wallet models.Wallet
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&wallet).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found in trash"})
+		return
+	}
+	role, err := walletRole(db, wallet, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load wallet"})
+		return
+	}
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found in trash"})
+		return
+	}
+	if roleRank[role] < roleRank[models.RoleOwner] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to perform this action on this wallet"})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return restoreWallet(tx, wallet)
+	})
+	if err != nil {
+		writeRestoreError(c, err)
+		return
+	}
+
+	db.First(&wallet, wallet.ID)
+	wallet.Role = role
+	c.JSON(http.StatusOK, gin.H{"data": wallet, "message": "Wallet restored"})
+}
+
+// RestoreCategory: Memulihkan kategori dari tempat sampah. Bila induknya juga
+// sudah dihapus, kategori dipulihkan sebagai kategori utama.
+func RestoreCategory(c *gin.Context) {
+	var category models.Category
+	db := c.MustGet("db").(*gorm.DB)
+
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+	if err := db.Unscoped().Scopes(tenantScope(c)).Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found in trash"})
+		return
+	}
+
+	updates := map[string]interface{}{"deleted_at": nil}
+	if category.ParentID != nil {
+		var parent models.Category
+		if err := db.First(&parent, *category.ParentID).Error; err != nil || parent.Type != category.Type {
+			updates["parent_id"] = nil
+		}
+	}
+	if err := db.Unscoped().Model(&category).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category"})
+		return
+	}
+
+	db.First(&category, category.ID)
+	c.JSON(http.StatusOK, gin.H{"data": category, "message": "Category restored"})
+}
+
+// purgeTransaction menghapus permanen transaksi dari tempat sampah beserta
+// tag, rincian split, lampiran dan refund-nya yang juga ada di tempat sampah
+// (pengeluaran dengan refund aktif tidak bisa masuk tempat sampah). Lampiran
+// dikembalikan agar filenya dihapus setelah commit.
+func purgeTransaction(tx *gorm.DB, transaction models.Transaction) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	var refunds []models.Transaction
+	if err := tx.Unscoped().Where("refund_of_id = ?", transaction.ID).Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	for _, refund := range refunds {
+		if !refund.DeletedAt.Valid {
+			return nil, errExpenseHasRefunds
+		}
+		refundAttachments, err := purgeTransaction(tx, refund)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, refundAttachments...)
+	}
+
+	var own []models.Attachment
+	if err := tx.Where("transaction_id = ?", transaction.ID).Find(&own).Error; err != nil {
+		return nil, err
+	}
+	attachments = append(attachments, own...)
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ?", transaction.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("transaction_id = ? OR other_transaction_id = ?", transaction.ID, transaction.ID).Delete(&models.DuplicateDismissal{}).Error; err != nil {
+		return nil, err
+	}
+	// Tagihan bersama tetap ada tanpa transaksi pengeluarannya
+	if err := tx.Model(&models.BillSplit{}).Where("transaction_id = ?", transaction.ID).Update("transaction_id", nil).Error; err != nil {
+		return nil, err
+	}
+	return attachments, tx.Unscoped().Delete(&transaction).Error
+}
+
+// purgeWallet menghapus permanen dompet dari tempat sampah. Dompet yang masih
+// dirujuk transaksi atau posting dilewati. Aturan dan rekonsiliasinya ikut
+// dihapus dan tercatat di audit log.
+func purgeWallet(tx *gorm.DB, wallet models.Wallet) error {
+	var used int64
+	tx.Unscoped().Model(&models.Transaction{}).Where("wallet_id = ?", wallet.ID).Count(&used)
+	if used == 0 {
+		tx.Model(&models.Posting{}).Joins("JOIN accounts ON accounts.id = postings.account_id").
+			Where("accounts.wallet_id = ?", wallet.ID).Count(&used)
+	}
+	if used > 0 {
+		return nil
+	}
+
+	for _, model := range []interface{}{&models.Account{}, &models.WalletInvitation{}, &models.WalletMember{}, &models.Reconciliation{}, &models.TransactionRule{}} {
+		if err := tx.Where("wallet_id = ?", wallet.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	// Tagihan bersama dan pelunasannya tetap disimpan tanpa dompet
+	for _, model := range []interface{}{&models.SavingsGoal{}, &models.BillSplit{}, &models.BillSettlement{}} {
+		if err := tx.Model(model).Where("wallet_id = ?", wallet.ID).Update("wallet_id", nil).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Delete(&wallet).Error
+}
+
+// purgeCategory menghapus permanen kategori dari tempat sampah. Kategori yang
+// masih dirujuk transaksi (termasuk yang di tempat sampah) dilewati.
+func purgeCategory(tx *gorm.DB, category models.Category) error {
+	var used int64
+	tx.Unscoped().Model(&models.Transaction{}).Where("category_id = ?", category.ID).Count(&used)
+	if used == 0 {
+		tx.Model(&models.TransactionSplit{}).Where("category_id = ?", category.ID).Count(&used)
+	}
+	if used == 0 {
+		tx.Model(&models.Posting{}).Joins("JOIN accounts ON accounts.id = postings.account_id").
+			Where("accounts.category_id = ?", category.ID).Count(&used)
+	}
+	if used > 0 {
+		return nil
+	}
+
+	if err := tx.Unscoped().Model(&models.Category{}).Where("parent_id = ?", category.ID).Update("parent_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("category_id = ?", category.ID).Delete(&models.Account{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&category).Error
+}
+
+// PurgeTrash menghapus permanen semua isi tempat sampah yang dihapus sebelum
+// cutoff, termasuk file lampirannya
+func PurgeTrash(db *gorm.DB, blobs storage.BlobStore, cutoff time.Time) error {
+	var transactions []models.Transaction
+	if err := db.Unscoped().Where("deleted_at < ?", cutoff).Find(&transactions).Error; err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		var attachments []models.Attachment
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			attachments, err = purgeTransaction(tx, transaction)
+			return err
+		})
+		// Pengeluaran yang masih punya refund aktif dilewati
+		if errors.Is(err, errExpenseHasRefunds) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, key := range attachmentKeys(attachments) {
+			if key == "" {
+				continue
+			}
+			if err := blobs.Delete(context.Background(), key); err != nil {
+				log.Println("Failed to delete blob", key+":", err)
+			}
+		}
+	}
+
+	// Jurnal transfer/saldo awal yang dibatalkan tidak punya posting lagi
+	if err := db.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.JournalEntry{}).Error; err != nil {
+		return err
+	}
+
+	var wallets []models.Wallet
+	if err := db.Unscoped().Where("deleted_at < ?", cutoff).Find(&wallets).Error; err != nil {
+		return err
+	}
+	for _, wallet := range wallets {
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeWallet(tx, wallet) }); err != nil {
+			return err
+		}
+	}
+
+	var categories []models.Category
+	if err := db.Unscoped().Where("deleted_at < ?", cutoff).Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeCategory(tx, category) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartTrashPurger menjalankan PurgeTrash di background setiap jam untuk
+// data yang sudah melewati masa simpan TRASH_RETENTION_DAYS
+func StartTrashPurger(db *gorm.DB, blobs storage.BlobStore) {
+	go func() {
+		for {
+			cutoff := time.Now().AddDate(0, 0, -trashRetentionDays())
+			if err := PurgeTrash(db, blobs, cutoff); err != nil {
+				log.Println("Failed to purge trash:", err)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+}
@@ -2,10 +2,13 @@ package controllers
 
 import (
 	"dompet/backend/models"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Helper function untuk mendapatkan user yang sedang login dari context
@@ -17,12 +20,44 @@ func getCurrentUser(c *gin.Context) (models.User, bool) {
 	return user.(models.User), true
 }
 
-// Struct untuk input saat membuat dompet
+// Struct untuk input saat membuat dompet. Untuk kartu kredit dan pinjaman,
+// Balance adalah jumlah yang masih terutang.
 type CreateWalletInput struct {
 	Name     string  `json:"name" binding:"required"`
+	Type     string  `json:"type" binding:"omitempty,oneof=cash bank ewallet credit_card loan investment"`
 	BankName string  `json:"bank_name"`
 	Currency string  `json:"currency"`
 	Balance  float64 `json:"balance"`
+
+	CreditLimit  *float64 `json:"credit_limit" binding:"omitempty,gt=0"`
+	StatementDay *int     `json:"statement_day" binding:"omitempty,min=1,max=31"`
+	DueDay       *int     `json:"due_day" binding:"omitempty,min=1,max=31"`
+	Principal    *float64 `json:"principal" binding:"omitempty,gt=0"`
+	InterestRate *float64 `json:"interest_rate" binding:"omitempty,min=0,max=100"`
+	BalanceCap   *float64 `json:"balance_cap" binding:"omitempty,gt=0"`
+
+	// Kartu kredit; kosong berarti batas bawah bawaan mata uang dompet
+	MinimumPaymentFloor *float64 `json:"minimum_payment_floor" binding:"omitempty,min=0"`
+}
+
+// validateWalletSettings memeriksa kelengkapan atribut sesuai jenis dompet
+func validateWalletSettings(wallet models.Wallet) error {
+	if wallet.Type != models.WalletCreditCard && (wallet.CreditLimit != nil || wallet.StatementDay != nil || wallet.DueDay != nil || wallet.MinimumPaymentFloor != nil) {
+		return errors.New("credit_limit, statement_day, due_day and minimum_payment_floor are only allowed for credit_card wallets")
+	}
+	if wallet.Type != models.WalletLoan && (wallet.Principal != nil || wallet.InterestRate != nil) {
+		return errors.New("principal and interest_rate are only allowed for loan wallets")
+	}
+	if wallet.Type != models.WalletEWallet && wallet.BalanceCap != nil {
+		return errors.New("balance_cap is only allowed for ewallet wallets")
+	}
+	if wallet.Type == models.WalletCreditCard && (wallet.CreditLimit == nil || wallet.StatementDay == nil || wallet.DueDay == nil) {
+		return errors.New("credit_card wallets require credit_limit, statement_day and due_day")
+	}
+	if wallet.Type == models.WalletLoan && wallet.Principal == nil {
+		return errors.New("loan wallets require principal")
+	}
+	return nil
 }
 
 // CreateWallet: Membuat dompet baru untuk user yang sedang login
@@ -31,6 +66,10 @@ func CreateWallet(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	currentUser, _ := getCurrentUser(c)
 
+	if !requireHouseholdRole(c, models.RoleEditor) {
+		return
+	}
+
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -41,74 +80,147 @@ func CreateWallet(c *gin.Context) {
 		walletCurrency = currentUser.Currency // Ambil dari default user
 	}
 
+	walletType := input.Type
+	if walletType == "" {
+		walletType = models.WalletCash
+	}
+
 	wallet := models.Wallet{
-		Name:     input.Name,
-		BankName: input.BankName,
-		Currency: walletCurrency,
-		Balance:  input.Balance,
-		UserID:   currentUser.ID,
+		Name:         input.Name,
+		Type:         walletType,
+		BankName:     input.BankName,
+		Currency:     walletCurrency,
+		UserID:       currentUser.ID,
+		HouseholdID:  getCurrentHousehold(c).ID,
+		CreditLimit:  input.CreditLimit,
+		StatementDay: input.StatementDay,
+		DueDay:       input.DueDay,
+		Principal:    input.Principal,
+		InterestRate: input.InterestRate,
+		BalanceCap:   input.BalanceCap,
+
+		MinimumPaymentFloor: input.MinimumPaymentFloor,
 	}
 
-	if err := db.Create(&wallet).Error; err != nil {
+	if err := validateWalletSettings(wallet); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Dompet kewajiban menyimpan utang sebagai saldo negatif. Pinjaman tanpa
+	// saldo dianggap masih terutang sebesar pokoknya.
+	openingBalance := input.Balance
+	if wallet.IsLiability() {
+		if openingBalance == 0 && wallet.Principal != nil {
+			openingBalance = *wallet.Principal
+		}
+		openingBalance = -openingBalance
+	}
+
+	// Saldo awal dibukukan sebagai jurnal terhadap akun ekuitas "Saldo Awal"
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&wallet).Error; err != nil {
+			return err
+		}
+		owner := models.WalletMember{WalletID: wallet.ID, UserID: currentUser.ID, Role: models.RoleOwner}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+		if _, err := createWalletAccount(tx, wallet); err != nil {
+			return err
+		}
+		return postOpeningBalance(tx, wallet, openingBalance)
+	})
+	if isWalletRuleError(err) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wallet"})
 		return
 	}
+	db.First(&wallet, wallet.ID)
+	wallet.Role = models.RoleOwner
 
 	c.JSON(http.StatusOK, gin.H{"data": wallet})
 }
 
-// GetAllWallets: Mendapatkan semua dompet milik user yang sedang login
+// GetAllWallets: Mendapatkan semua dompet milik user yang sedang login,
+// termasuk dompet bersama yang ia ikuti
 func GetAllWallets(c *gin.Context) {
 	var wallets []models.Wallet
 	db := c.MustGet("db").(*gorm.DB)
 	currentUser, _ := getCurrentUser(c)
 
-	db.Where("user_id = ?", currentUser.ID).Find(&wallets)
+	query := db.Where("id IN (?)", accessibleWalletIDs(c, db))
+	if c.Query("include_archived") != "true" {
+		query = query.Where("archived_at IS NULL")
+	}
+	query.Find(&wallets)
+	fillWalletRoles(db, wallets, currentUser.ID)
 
 	c.JSON(http.StatusOK, gin.H{"data": wallets})
 }
 
 // GetWalletByID: Mendapatkan satu dompet berdasarkan ID
 func GetWalletByID(c *gin.Context) {
-	var wallet models.Wallet
 	db := c.MustGet("db").(*gorm.DB)
-	currentUser, _ := getCurrentUser(c)
 
-	// Cari dompet berdasarkan ID dari URL
-	if err := db.Where("id = ?", c.Param("id")).First(&wallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
-		return
-	}
-
-	// Pastikan dompet ini milik user yang sedang login
-	if wallet.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to view this wallet"})
+	// Cari dompet berdasarkan ID dari URL, pastikan user adalah anggotanya
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleViewer)
+	if !ok {
 		return
 	}
 
+	c.Header("ETag", etagFor(wallet.Version))
 	c.JSON(http.StatusOK, gin.H{"data": wallet})
 }
 
 // Struct untuk input saat update dompet
 type UpdateWalletInput struct {
 	Name     string `json:"name"`
+	Type     string `json:"type" binding:"omitempty,oneof=cash bank ewallet credit_card loan investment"`
 	BankName string `json:"bank_name"`
+
+	CreditLimit  *float64 `json:"credit_limit" binding:"omitempty,gt=0"`
+	StatementDay *int     `json:"statement_day" binding:"omitempty,min=1,max=31"`
+	DueDay       *int     `json:"due_day" binding:"omitempty,min=1,max=31"`
+	Principal    *float64 `json:"principal" binding:"omitempty,gt=0"`
+	InterestRate *float64 `json:"interest_rate" binding:"omitempty,min=0,max=100"`
+	BalanceCap   *float64 `json:"balance_cap" binding:"omitempty,gt=0"`
+
+	// Kartu kredit; kosong berarti batas bawah bawaan mata uang dompet
+	MinimumPaymentFloor *float64 `json:"minimum_payment_floor" binding:"omitempty,min=0"`
 }
 
-// UpdateWallet: Memperbarui nama dompet
+// clearWalletSettings mengosongkan atribut yang tidak berlaku untuk jenis
+// dompet, dipakai saat jenis dompet diganti
+func clearWalletSettings(wallet *models.Wallet) {
+	if wallet.Type != models.WalletCreditCard {
+		wallet.CreditLimit, wallet.StatementDay, wallet.DueDay = nil, nil, nil
+		wallet.MinimumPaymentFloor = nil
+		wallet.AvailableCredit = nil
+	}
+	if wallet.Type != models.WalletLoan {
+		wallet.Principal, wallet.InterestRate = nil, nil
+	}
+	if wallet.Type != models.WalletEWallet {
+		wallet.BalanceCap = nil
+	}
+}
+
+// UpdateWallet: Memperbarui nama dan pengaturan dompet (hanya owner).
+// Header If-Match berisi ETag dari GET mencegah perubahan yang saling timpa.
+// Saat jenis dompet diganti, atribut jenis lama yang tidak berlaku dihapus.
 func UpdateWallet(c *gin.Context) {
-	var wallet models.Wallet
 	var input UpdateWalletInput
 	db := c.MustGet("db").(*gorm.DB)
-	currentUser, _ := getCurrentUser(c)
 
-	if err := db.Where("id = ?", c.Param("id")).First(&wallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
 		return
 	}
-
-	if wallet.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to update this wallet"})
+	if !checkIfMatch(c, wallet.Version) {
 		return
 	}
 
@@ -117,32 +229,388 @@ func UpdateWallet(c *gin.Context) {
 		return
 	}
 
-	if err := db.Model(&wallet).Updates(input).Error; err != nil {
+	// Dompet aset tidak bisa diubah menjadi kewajiban (dan sebaliknya) karena
+	// arti saldonya berbeda
+	updated := wallet
+	if input.Name != "" {
+		updated.Name = input.Name
+	}
+	if input.BankName != "" {
+		updated.BankName = input.BankName
+	}
+	if input.Type != "" && input.Type != wallet.Type {
+		updated.Type = input.Type
+		clearWalletSettings(&updated)
+	}
+	if updated.IsLiability() != wallet.IsLiability() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change a wallet between asset and liability types"})
+		return
+	}
+	if input.CreditLimit != nil {
+		updated.CreditLimit = input.CreditLimit
+	}
+	if input.StatementDay != nil {
+		updated.StatementDay = input.StatementDay
+	}
+	if input.DueDay != nil {
+		updated.DueDay = input.DueDay
+	}
+	if input.MinimumPaymentFloor != nil {
+		updated.MinimumPaymentFloor = input.MinimumPaymentFloor
+	}
+	if input.Principal != nil {
+		updated.Principal = input.Principal
+	}
+	if input.InterestRate != nil {
+		updated.InterestRate = input.InterestRate
+	}
+	if input.BalanceCap != nil {
+		updated.BalanceCap = input.BalanceCap
+	}
+	if err := validateWalletSettings(updated); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, &models.Wallet{}, wallet.ID, wallet.Version); err != nil {
+			return err
+		}
+		// Kolom dipilih eksplisit agar atribut yang dikosongkan ikut tersimpan sebagai NULL
+		if err := tx.Model(&wallet).Select("Name", "Type", "BankName", "CreditLimit", "StatementDay", "DueDay",
+			"MinimumPaymentFloor", "Principal", "InterestRate", "BalanceCap").Updates(&updated).Error; err != nil {
+			return err
+		}
+		if input.Name != "" {
+			return tx.Model(&models.Account{}).Where("wallet_id = ?", wallet.ID).Update("name", input.Name).Error
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update wallet"})
 		return
 	}
 	// db.Model(&wallet).Updates(input)
+	updated.Version++
+	updated.AfterFind(db)
+
+	c.Header("ETag", etagFor(updated.Version))
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
 
+// ArchiveWallet: Mengarsipkan dompet tanpa menghapus riwayatnya
+func ArchiveWallet(c *gin.Context) {
+	setWalletArchived(c, true)
+}
+
+// UnarchiveWallet: Mengaktifkan kembali dompet yang diarsipkan
+func UnarchiveWallet(c *gin.Context) {
+	setWalletArchived(c, false)
+}
+
+func setWalletArchived(c *gin.Context, archived bool) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+	if err := db.Model(&wallet).Updates(map[string]interface{}{"archived_at": archivedAt, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update wallet"})
+		return
+	}
+	wallet.ArchivedAt = archivedAt
+	wallet.Version++
+
+	c.Header("ETag", etagFor(wallet.Version))
 	c.JSON(http.StatusOK, gin.H{"data": wallet})
 }
 
-// DeleteWallet: Menghapus dompet
+// Mode penghapusan dompet yang masih memiliki riwayat
+const (
+	DeleteWalletCascade = "cascade"
+	DeleteWalletMove    = "move"
+)
+
+// errWalletReconciled dikembalikan bila riwayat dompet yang sudah
+// direkonsiliasi akan dipindahkan ke dompet lain
+var errWalletReconciled = errors.New("wallet has reconciled transactions; unlock them before moving its history to another wallet")
+
+// DeleteWallet: Menghapus dompet. Dompet yang sudah punya riwayat hanya bisa
+// dihapus dengan ?mode=cascade (transaksinya ikut masuk tempat sampah) atau
+// ?mode=move&target_wallet_id=ID (transaksi dan saldonya dipindahkan ke dompet
+// lain, lalu dompet dihapus permanen).
 func DeleteWallet(c *gin.Context) {
-	var wallet models.Wallet
 	db := c.MustGet("db").(*gorm.DB)
 	currentUser, _ := getCurrentUser(c)
 
-	if err := db.Where("id = ?", c.Param("id")).First(&wallet).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	mode := c.Query("mode")
+	var target models.Wallet
+	switch mode {
+	case "", DeleteWalletCascade:
+	case DeleteWalletMove:
+		var err error
+		target, err = findWalletWithRole(db, c.Query("target_wallet_id"), currentUser.ID, models.RoleOwner)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "target_wallet_id must be another wallet you own"})
+			return
+		}
+		if target.ID == wallet.ID || target.Currency != wallet.Currency || target.HouseholdID != wallet.HouseholdID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target wallet must be a different wallet with the same currency in the same household"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be either cascade or move"})
+		return
+	}
+
+	if mode == "" {
+		hasHistory, err := walletHasHistory(db, wallet)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet"})
+			return
+		}
+		if hasHistory {
+			c.JSON(http.StatusConflict, gin.H{"error": "Wallet has transactions. Archive it, or delete with mode=cascade or mode=move&target_wallet_id=ID"})
+			return
+		}
+	}
+
+	// Dompet yang riwayatnya dipindahkan langsung dihapus permanen karena
+	// tidak ada lagi yang bisa dipulihkan bersamanya
+	if mode == DeleteWalletMove {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := moveWalletHistory(tx, wallet, target); err != nil {
+				return err
+			}
+			// Target tabungan ikut pindah ke dompet yang menampung dananya
+			if err := tx.Model(&models.SavingsGoal{}).Where("wallet_id = ?", wallet.ID).Update("wallet_id", target.ID).Error; err != nil {
+				return err
+			}
+			return purgeWallet(tx, wallet)
+		})
+		if errors.Is(err, errWalletReconciled) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isWalletRuleError(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": true, "message": "Wallet merged into the target wallet and deleted"})
 		return
 	}
 
-	if wallet.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this wallet"})
+	// Dompet masuk tempat sampah. Semua yang ikut terhapus diberi waktu hapus
+	// yang sama agar bisa dipulihkan bersama dompetnya.
+	deletedAt := time.Now().Truncate(time.Microsecond)
+	err := db.Session(&gorm.Session{NowFunc: func() time.Time { return deletedAt }}).Transaction(func(tx *gorm.DB) error {
+		if err := removeWalletHistory(tx, wallet); err != nil {
+			return err
+		}
+		// Undangan yang belum dijawab tidak berlaku lagi. Anggota, aturan dan
+		// rekonsiliasi disimpan sampai dompet dibersihkan dari tempat sampah.
+		if err := tx.Where("wallet_id = ?", wallet.ID).Delete(&models.WalletInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&wallet).Error
+	})
+
+	if errors.Is(err, errExpenseHasRefunds) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A transaction in this wallet has refunds in another wallet; delete those refunds first"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet: " + err.Error()})
 		return
 	}
 
-	db.Delete(&wallet)
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Wallet moved to trash"})
+}
+
+// walletHasHistory bernilai true bila dompet punya transaksi atau jurnal
+// selain saldo awal
+func walletHasHistory(db *gorm.DB, wallet models.Wallet) (bool, error) {
+	var count int64
+	if err := db.Model(&models.Transaction{}).Where("wallet_id = ?", wallet.ID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err := db.Model(&models.Posting{}).
+		Joins("JOIN accounts ON accounts.id = postings.account_id").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("accounts.wallet_id = ? AND journal_entries.kind <> ?", wallet.ID, models.JournalOpening).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// walletJournalEntries mengambil semua jurnal yang menyentuh akun tertentu
+func walletJournalEntries(tx *gorm.DB, accountID uint) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := tx.Where("id IN (?)", tx.Model(&models.Posting{}).Select("journal_entry_id").Where("account_id = ?", accountID)).
+		Find(&entries).Error
+	return entries, err
+}
+
+// removeWalletHistory memindahkan semua transaksi dompet ke tempat sampah dan
+// membatalkan jurnal lainnya (transfer, saldo awal). Saldo dompet lain yang
+// terlibat transfer ikut dikembalikan. Refund dihapus lebih dulu agar
+// pengeluaran asalnya di dompet yang sama ikut bisa dihapus.
+func removeWalletHistory(tx *gorm.DB, wallet models.Wallet) error {
+	var transactions []models.Transaction
+	if err := tx.Where("wallet_id = ?", wallet.ID).Order("refund_of_id IS NULL, id").Find(&transactions).Error; err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		if err := removeTransaction(tx, transaction); err != nil {
+			return err
+		}
+	}
+
+	account, err := walletAccount(tx, wallet)
+	if err != nil {
+		return err
+	}
+	entries, err := walletJournalEntries(tx, account.ID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := voidJournal(tx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveWalletHistory memindahkan semua transaksi dan posting dompet ke dompet
+// target, sehingga saldo dompet target bertambah sebesar saldo dompet asal
+// dan saldo dompet asal menjadi nol. Rekonsiliasi dompet asal tidak berlaku
+// untuk dompet target, jadi transaksi yang sudah direkonsiliasi harus dibuka
+// kuncinya dulu secara eksplisit.
+func moveWalletHistory(tx *gorm.DB, wallet, target models.Wallet) error {
+	var reconciled int64
+	if err := tx.Unscoped().Model(&models.Transaction{}).Where("wallet_id = ? AND status = ?", wallet.ID, models.TransactionReconciled).
+		Count(&reconciled).Error; err != nil {
+		return err
+	}
+	if reconciled > 0 {
+		return errWalletReconciled
+	}
+
+	source, err := walletAccount(tx, wallet)
+	if err != nil {
+		return err
+	}
+	destination, err := walletAccount(tx, target)
+	if err != nil {
+		return err
+	}
+
+	// Transfer antara kedua dompet tidak bermakna lagi setelah digabung
+	var transfers []models.JournalEntry
+	err = tx.Where("id IN (?) AND id IN (?)",
+		tx.Model(&models.Posting{}).Select("journal_entry_id").Where("account_id = ?", source.ID),
+		tx.Model(&models.Posting{}).Select("journal_entry_id").Where("account_id = ?", destination.ID),
+	).Find(&transfers).Error
+	if err != nil {
+		return err
+	}
+	for _, entry := range transfers {
+		if err := deleteJournal(tx, entry); err != nil {
+			return err
+		}
+	}
+
+	var moved struct{ Total float64 }
+	if err := tx.Model(&models.Posting{}).Select("COALESCE(SUM(debit - credit), 0) AS total").
+		Where("account_id = ?", source.ID).Scan(&moved).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Posting{}).Where("account_id = ?", source.ID).Update("account_id", destination.ID).Error; err != nil {
+		return err
+	}
+	// Transaksi di tempat sampah ikut dipindahkan
+	if err := tx.Unscoped().Model(&models.Transaction{}).Where("wallet_id = ?", wallet.ID).
+		Updates(map[string]interface{}{"wallet_id": target.ID, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return err
+	}
+	// Semua rujukan lain ke dompet asal ikut pindah ke dompet target
+	for _, model := range []interface{}{&models.BillSplit{}, &models.BillSettlement{}, &models.TransactionRule{}, &models.Reconciliation{}} {
+		if err := tx.Model(model).Where("wallet_id = ?", wallet.ID).Update("wallet_id", target.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	// Saldo gabungan tetap harus memenuhi limit dompet target
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&target, target.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Wallet{}).Where("id = ?", target.ID).
+		Update("balance", gorm.Expr("balance + ?", moved.Total)).Error; err != nil {
+		return err
+	}
+	target.Balance += moved.Total
+	if err := checkWalletLimits(target, moved.Total); err != nil {
+		return err
+	}
+	return tx.Model(&models.Wallet{}).Where("id = ?", wallet.ID).Update("balance", 0).Error
+}
+
+type NetWorthSummary struct {
+	Currency    string  `json:"currency"`
+	Assets      float64 `json:"assets"`
+	Liabilities float64 `json:"liabilities"`
+	NetWorth    float64 `json:"net_worth"`
+}
+
+// GetNetWorth: Menghitung kekayaan bersih per mata uang. Saldo kartu kredit
+// dan pinjaman dihitung sebagai kewajiban yang mengurangi kekayaan bersih.
+func GetNetWorth(c *gin.Context) {
+	var wallets []models.Wallet
+	db := c.MustGet("db").(*gorm.DB)
+
+	db.Where("id IN (?)", accessibleWalletIDs(c, db)).Find(&wallets)
+
+	summaries := []*NetWorthSummary{}
+	byCurrency := map[string]*NetWorthSummary{}
+	for _, wallet := range wallets {
+		summary, ok := byCurrency[wallet.Currency]
+		if !ok {
+			summary = &NetWorthSummary{Currency: wallet.Currency}
+			byCurrency[wallet.Currency] = summary
+			summaries = append(summaries, summary)
+		}
+		if wallet.IsLiability() {
+			summary.Liabilities -= wallet.Balance
+		} else {
+			summary.Assets += wallet.Balance
+		}
+		summary.NetWorth = summary.Assets - summary.Liabilities
+	}
 
-	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Wallet deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": summaries})
 }
@@ -0,0 +1,350 @@
+package controllers
+
+import (
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errWalletForbidden = errors.New("you are not authorized to access this wallet")
+
+// roleRank mengurutkan peran dari yang paling terbatas
+var roleRank = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleOwner:  3,
+}
+
+// walletRole mengembalikan peran user pada dompet, atau "" bila tidak punya
+// akses. Pemilik dompet (wallet.UserID) dianggap owner selama ia masih anggota
+// household dompet, termasuk dompet lama yang belum punya baris keanggotaan.
+// Selain itu dipakai peran tertinggi antara keanggotaan household dompet dan
+// keanggotaan dompet bersama.
+func walletRole(db *gorm.DB, wallet models.Wallet, userID uint) (string, error) {
+	role := ""
+	var householdMember models.HouseholdMember
+	err := db.Where("household_id = ? AND user_id = ?", wallet.HouseholdID, userID).First(&householdMember).Error
+	if err == nil {
+		if wallet.UserID == userID {
+			return models.RoleOwner, nil
+		}
+		role = householdMember.Role
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	var member models.WalletMember
+	err = db.Where("wallet_id = ? AND user_id = ?", wallet.ID, userID).First(&member).Error
+	if err == nil && roleRank[member.Role] > roleRank[role] {
+		role = member.Role
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	return role, nil
+}
+
+// findWalletWithRole mencari dompet dan memastikan user punya minimal peran minRole
+func findWalletWithRole(db *gorm.DB, walletID interface{}, userID uint, minRole string) (models.Wallet, error) {
+	var wallet models.Wallet
+	if err := db.First(&wallet, "id = ?", walletID).Error; err != nil {
+		return wallet, err
+	}
+	role, err := walletRole(db, wallet, userID)
+	if err != nil {
+		return wallet, err
+	}
+	if roleRank[role] < roleRank[minRole] {
+		if role == "" {
+			return wallet, gorm.ErrRecordNotFound
+		}
+		return wallet, errWalletForbidden
+	}
+	wallet.Role = role
+	return wallet, nil
+}
+
+// loadWalletWithRole seperti findWalletWithRole, tetapi langsung menulis
+// respons 404/403 bila gagal
+func loadWalletWithRole(c *gin.Context, db *gorm.DB, walletID interface{}, minRole string) (models.Wallet, bool) {
+	currentUser, _ := getCurrentUser(c)
+	wallet, err := findWalletWithRole(db, walletID, currentUser.ID, minRole)
+	switch {
+	case err == nil:
+		return wallet, true
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	case errors.Is(err, errWalletForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to perform this action on this wallet"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load wallet"})
+	}
+	return wallet, false
+}
+
+// sharedWalletIDs adalah subquery ID dompet yang dibagikan langsung ke user
+func sharedWalletIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.WalletMember{}).Select("wallet_id").Where("user_id = ?", userID)
+}
+
+// accessibleWalletIDs adalah subquery ID dompet yang terlihat di household
+// aktif: dompet milik household itu ditambah dompet yang dibagikan ke user
+func accessibleWalletIDs(c *gin.Context, db *gorm.DB) *gorm.DB {
+	currentUser, _ := getCurrentUser(c)
+	return db.Model(&models.Wallet{}).Select("id").
+		Where("household_id = ? OR id IN (?)", getCurrentHousehold(c).ID, sharedWalletIDs(db, currentUser.ID))
+}
+
+// fillWalletRoles mengisi peran user pada setiap dompet
+func fillWalletRoles(db *gorm.DB, wallets []models.Wallet, userID uint) {
+	for i := range wallets {
+		wallets[i].Role, _ = walletRole(db, wallets[i], userID)
+	}
+}
+
+// GetWalletMembers: Mendapatkan daftar anggota dompet
+func GetWalletMembers(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var members []models.WalletMember
+	db.Preload("User").Where("wallet_id = ?", wallet.ID).Order("created_at").Find(&members)
+
+	// Dompet lama belum punya baris keanggotaan untuk pemiliknya
+	hasOwner := false
+	for _, member := range members {
+		if member.UserID == wallet.UserID {
+			hasOwner = true
+		}
+	}
+	if !hasOwner {
+		var owner models.User
+		db.First(&owner, wallet.UserID)
+		members = append([]models.WalletMember{{WalletID: wallet.ID, UserID: owner.ID, Role: models.RoleOwner, User: owner}}, members...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+type UpdateMemberInput struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// UpdateWalletMember: Mengubah peran anggota (hanya owner)
+func UpdateWalletMember(c *gin.Context) {
+	var input UpdateMemberInput
+	db := c.MustGet("db").(*gorm.DB)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var member models.WalletMember
+	if err := db.Where("wallet_id = ? AND user_id = ?", wallet.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if member.Role == models.RoleOwner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner's role cannot be changed"})
+		return
+	}
+
+	db.Model(&member).Update("role", input.Role)
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// RemoveWalletMember: Mengeluarkan anggota. Owner bisa mengeluarkan siapa saja,
+// anggota lain hanya bisa keluar sendiri.
+func RemoveWalletMember(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	var member models.WalletMember
+	if err := db.Where("wallet_id = ? AND user_id = ?", wallet.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if member.Role == models.RoleOwner || member.UserID == wallet.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner cannot be removed from the wallet"})
+		return
+	}
+	if wallet.Role != models.RoleOwner && member.UserID != currentUser.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can remove other members"})
+		return
+	}
+
+	db.Delete(&member)
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Member removed successfully"})
+}
+
+type InvitationInput struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// CreateWalletInvitation: Mengundang user lain lewat email (hanya owner)
+func CreateWalletInvitation(c *gin.Context) {
+	var input InvitationInput
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+
+	if strings.EqualFold(email, currentUser.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot invite yourself"})
+		return
+	}
+
+	var invitee models.User
+	if err := db.Where("LOWER(email) = ?", email).First(&invitee).Error; err == nil {
+		if role, _ := walletRole(db, wallet, invitee.ID); role != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this wallet"})
+			return
+		}
+	}
+
+	var existing models.WalletInvitation
+	if err := db.Where("wallet_id = ? AND email = ? AND status = ?", wallet.ID, email, models.InvitationPending).First(&existing).Error; err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "An invitation for this email is already pending"})
+		return
+	}
+
+	invitation := models.WalletInvitation{
+		WalletID:    wallet.ID,
+		Email:       email,
+		Role:        input.Role,
+		InvitedByID: currentUser.ID,
+		Status:      models.InvitationPending,
+	}
+	if err := db.Create(&invitation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitation})
+}
+
+// GetWalletInvitations: Mendapatkan undangan yang dikirim untuk dompet (hanya owner)
+func GetWalletInvitations(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var invitations []models.WalletInvitation
+	db.Where("wallet_id = ?", wallet.ID).Order("created_at desc").Find(&invitations)
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// RevokeWalletInvitation: Membatalkan undangan yang belum dijawab (hanya owner)
+func RevokeWalletInvitation(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	wallet, ok := loadWalletWithRole(c, db, c.Param("id"), models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var invitation models.WalletInvitation
+	if err := db.Where("id = ? AND wallet_id = ? AND status = ?", c.Param("invitationId"), wallet.ID, models.InvitationPending).First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	now := time.Now()
+	db.Model(&invitation).Updates(models.WalletInvitation{Status: models.InvitationRevoked, RespondedAt: &now})
+	c.JSON(http.StatusOK, gin.H{"data": true, "message": "Invitation revoked"})
+}
+
+// GetMyInvitations: Mendapatkan undangan yang menunggu jawaban user
+func GetMyInvitations(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	var invitations []models.WalletInvitation
+	db.Preload("Wallet").Preload("InvitedBy").
+		Where("email = ? AND status = ?", strings.ToLower(currentUser.Email), models.InvitationPending).
+		Order("created_at desc").Find(&invitations)
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// AcceptInvitation: Menerima undangan dan menjadi anggota dompet
+func AcceptInvitation(c *gin.Context) {
+	respondToInvitation(c, true)
+}
+
+// DeclineInvitation: Menolak undangan
+func DeclineInvitation(c *gin.Context) {
+	respondToInvitation(c, false)
+}
+
+func respondToInvitation(c *gin.Context, accept bool) {
+	var invitation models.WalletInvitation
+	db := c.MustGet("db").(*gorm.DB)
+	currentUser, _ := getCurrentUser(c)
+
+	if err := db.Where("id = ? AND email = ? AND status = ?", c.Param("id"), strings.ToLower(currentUser.Email), models.InvitationPending).First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	now := time.Now()
+	status := models.InvitationDeclined
+	if accept {
+		status = models.InvitationAccepted
+	}
+
+	var member models.WalletMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&invitation).Updates(models.WalletInvitation{Status: status, RespondedAt: &now}).Error; err != nil {
+			return err
+		}
+		if !accept {
+			return nil
+		}
+		member = models.WalletMember{WalletID: invitation.WalletID, UserID: currentUser.ID, Role: invitation.Role}
+		return tx.Where("wallet_id = ? AND user_id = ?", invitation.WalletID, currentUser.ID).FirstOrCreate(&member).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to respond to invitation"})
+		return
+	}
+
+	if !accept {
+		c.JSON(http.StatusOK, gin.H{"data": true, "message": "Invitation declined"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": member, "message": "Invitation accepted"})
+}
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"dompet/backend/models"
+	"encoding/json"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditedTables memetakan tabel yang perubahannya dicatat ke nama entitas
+// di audit log
+var auditedTables = map[string]string{
+	"wallets":           models.AuditEntityWallet,
+	"categories":        models.AuditEntityCategory,
+	"transactions":      models.AuditEntityTransaction,
+	"users":             models.AuditEntityProfile,
+	"transaction_rules": models.AuditEntityRule,
+	"reconciliations":   models.AuditEntityReconciliation,
+}
+
+// auditRedactedColumns tidak pernah ditulis ke audit log
+var auditRedactedColumns = map[string]bool{"password_hash": true}
+
+// auditBeforeKey menyimpan kondisi baris sebelum update/delete di Statement.Settings
+const auditBeforeKey = "audit:before"
+
+type auditContextKey struct{}
+
+// AuditActor adalah pelaku dan asal request yang dicatat bersama perubahan
+type AuditActor struct {
+	UserID    *uint
+	IP        string
+	RequestID string
+	Method    string
+	Path      string
+}
+
+// WithAuditActor menyimpan pelaku ke context. Query yang memakai
+// db.WithContext(ctx) akan mencatat pelaku ini di audit log.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, actor)
+}
+
+// RegisterAuditCallbacks memasang callback GORM yang mencatat setiap create,
+// update dan delete pada tabel di auditedTables, di dalam database
+// transaction yang sama dengan perubahannya
+func RegisterAuditCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", auditBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", auditAfterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", auditBefore); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", auditAfterDelete)
+}
+
+// auditEntity mengembalikan nama entitas bila tabel statement diaudit
+func auditEntity(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return "", false
+	}
+	entity, ok := auditedTables[db.Statement.Table]
+	return entity, ok
+}
+
+// auditRow mengubah satu struct model menjadi map kolom -> nilai
+func auditRow(stmt *gorm.Statement, value reflect.Value) map[string]interface{} {
+	row := map[string]interface{}{}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || auditRedactedColumns[field.DBName] {
+			continue
+		}
+		row[field.DBName], _ = field.ValueOf(stmt.Context, value)
+	}
+	return row
+}
+
+// auditTargetQuery menyusun query yang memilih baris-baris yang akan
+// diubah/dihapus statement. Hasil false berarti statement tidak punya
+// kondisi sehingga tidak perlu dicatat (GORM akan menolaknya).
+func auditTargetQuery(db *gorm.DB) (*gorm.DB, bool) {
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table)
+	hasConditions := false
+
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(where)
+			hasConditions = true
+		}
+	}
+	primaryKey := stmt.Schema.PrioritizedPrimaryField
+	if primaryKey != nil && stmt.ReflectValue.Kind() == reflect.Struct {
+		if value, zero := primaryKey.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			query = query.Where(clause.Eq{Column: clause.Column{Table: stmt.Table, Name: primaryKey.DBName}, Value: value})
+			hasConditions = true
+		}
+	}
+	return query, hasConditions
+}
+
+// loadAuditRows membaca baris yang dipilih query sebagai map kolom -> nilai
+func loadAuditRows(query *gorm.DB) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for column := range auditRedactedColumns {
+			delete(row, column)
+		}
+	}
+	return rows, nil
+}
+
+// auditBefore menyimpan kondisi baris sebelum update/delete dijalankan
+func auditBefore(db *gorm.DB) {
+	if _, ok := auditEntity(db); !ok {
+		return
+	}
+	query, ok := auditTargetQuery(db)
+	if !ok {
+		return
+	}
+	rows, err := loadAuditRows(query)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.Statement.Settings.Store(auditBeforeKey, rows)
+}
+
+func auditAfterCreate(db *gorm.DB) {
+	entity, ok := auditEntity(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+
+	var logs []models.AuditLog
+	add := func(value reflect.Value) {
+		value = reflect.Indirect(value)
+		if value.Kind() == reflect.Struct {
+			logs = append(logs, newAuditLog(db, entity, models.AuditCreate, nil, auditRow(stmt, value)))
+		}
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			add(stmt.ReflectValue.Index(i))
+		}
+	default:
+		add(stmt.ReflectValue)
+	}
+	saveAuditLogs(db, logs)
+}
+
+func auditAfterUpdate(db *gorm.DB) {
+	entity, ok := auditEntity(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+	stored, ok := db.Statement.Settings.Load(auditBeforeKey)
+	if !ok {
+		return
+	}
+	before := stored.([]map[string]interface{})
+	if len(before) == 0 {
+		return
+	}
+
+	primaryKey := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	ids := make([]interface{}, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row[primaryKey])
+	}
+	after, err := loadAuditRows(db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table).
+		Where(clause.IN{Column: clause.Column{Name: primaryKey}, Values: ids}))
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	afterByID := map[interface{}]map[string]interface{}{}
+	for _, row := range after {
+		afterByID[row[primaryKey]] = row
+	}
+
+	// Hanya kolom yang berubah yang disimpan
+	var logs []models.AuditLog
+	for _, old := range before {
+		current, ok := afterByID[old[primaryKey]]
+		if !ok {
+			continue
+		}
+		oldDiff, newDiff := map[string]interface{}{primaryKey: old[primaryKey]}, map[string]interface{}{primaryKey: old[primaryKey]}
+		changed := false
+		for column, value := range current {
+			if !reflect.DeepEqual(old[column], value) {
+				oldDiff[column], newDiff[column] = old[column], value
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		// household_id disertakan agar log tetap bisa difilter per household
+		if householdID, ok := current["household_id"]; ok {
+			oldDiff["household_id"], newDiff["household_id"] = old["household_id"], householdID
+		}
+		logs = append(logs, newAuditLog(db, entity, models.AuditUpdate, oldDiff, newDiff))
+	}
+	saveAuditLogs(db, logs)
+}
+
+func auditAfterDelete(db *gorm.DB) {
+	entity, ok := auditEntity(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+	stored, ok := db.Statement.Settings.Load(auditBeforeKey)
+	if !ok {
+		return
+	}
+
+	var logs []models.AuditLog
+	for _, row := range stored.([]map[string]interface{}) {
+		logs = append(logs, newAuditLog(db, entity, models.AuditDelete, row, nil))
+	}
+	saveAuditLogs(db, logs)
+}
+
+// newAuditLog menyusun satu baris audit log dari kondisi sebelum dan sesudah
+func newAuditLog(db *gorm.DB, entity, action string, before, after map[string]interface{}) models.AuditLog {
+	log := models.AuditLog{Entity: entity, Action: action}
+	if actor, ok := db.Statement.Context.Value(auditContextKey{}).(AuditActor); ok {
+		log.UserID = actor.UserID
+		log.IP = actor.IP
+		log.RequestID = actor.RequestID
+		log.Method = actor.Method
+		log.Path = actor.Path
+	}
+
+	row := after
+	if row == nil {
+		row = before
+	}
+	primaryKey := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	log.EntityID = toUint(row[primaryKey])
+	if householdID := toUint(row["household_id"]); householdID != 0 {
+		log.HouseholdID = &householdID
+	}
+
+	if before != nil {
+		log.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		log.After, _ = json.Marshal(after)
+	}
+	return log
+}
+
+// saveAuditLogs menulis audit log memakai koneksi statement, sehingga ikut
+// di-rollback bila perubahannya gagal
+func saveAuditLogs(db *gorm.DB, logs []models.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+// toUint mengubah nilai ID hasil scan database (int64, uint, dst.) ke uint
+func toUint(value interface{}) uint {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(v.Uint())
+	}
+	return 0
+}
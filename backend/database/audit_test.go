@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"dompet/backend/database/dbtest"
+	"dompet/backend/models"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func openAuditedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t)
+	if err := RegisterAuditCallbacks(db); err != nil {
+		t.Fatalf("RegisterAuditCallbacks() error = %v", err)
+	}
+	return db
+}
+
+// auditLogs mengambil audit log sebuah entitas, urut dari yang paling lama
+func auditLogs(t *testing.T, db *gorm.DB, entity string) []models.AuditLog {
+	t.Helper()
+	var logs []models.AuditLog
+	if err := db.Where("entity = ?", entity).Order("id").Find(&logs).Error; err != nil {
+		t.Fatalf("load audit logs: %v", err)
+	}
+	return logs
+}
+
+func decode(t *testing.T, data json.RawMessage) map[string]interface{} {
+	t.Helper()
+	if data == nil {
+		return nil
+	}
+	row := map[string]interface{}{}
+	if err := json.Unmarshal(data, &row); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return row
+}
+
+func TestAuditCallbacks(t *testing.T) {
+	db := openAuditedDB(t)
+	actorID := uint(7)
+	ctx := WithAuditActor(context.Background(), AuditActor{
+		UserID: &actorID, IP: "10.0.0.1", RequestID: "req-1", Method: "POST", Path: "/api/wallets",
+	})
+	tx := db.WithContext(ctx)
+
+	wallet := models.Wallet{UserID: actorID, HouseholdID: 3, Name: "Tunai", Type: models.WalletCash, Currency: "IDR", Balance: 100}
+	if err := tx.Create(&wallet).Error; err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+	if err := tx.Model(&wallet).Update("name", "Dompet Harian").Error; err != nil {
+		t.Fatalf("update wallet: %v", err)
+	}
+	// Update tanpa perubahan nilai tidak dicatat. UpdateColumn dipakai agar
+	// updated_at juga tidak berubah.
+	if err := tx.Model(&wallet).UpdateColumn("name", "Dompet Harian").Error; err != nil {
+		t.Fatalf("no-op update: %v", err)
+	}
+	if err := tx.Delete(&wallet).Error; err != nil {
+		t.Fatalf("delete wallet: %v", err)
+	}
+
+	logs := auditLogs(t, db, models.AuditEntityWallet)
+	if len(logs) != 3 {
+		t.Fatalf("got %d wallet audit logs, want create, update and delete", len(logs))
+	}
+	for i, action := range []string{models.AuditCreate, models.AuditUpdate, models.AuditDelete} {
+		log := logs[i]
+		if log.Action != action || log.EntityID != wallet.ID {
+			t.Errorf("log %d = %s #%d, want %s #%d", i, log.Action, log.EntityID, action, wallet.ID)
+		}
+		if log.UserID == nil || *log.UserID != actorID || log.IP != "10.0.0.1" || log.RequestID != "req-1" {
+			t.Errorf("log %d actor = %v %q %q, want user %d from the context", i, log.UserID, log.IP, log.RequestID, actorID)
+		}
+		if log.HouseholdID == nil || *log.HouseholdID != 3 {
+			t.Errorf("log %d household = %v, want 3", i, log.HouseholdID)
+		}
+	}
+
+	created := decode(t, logs[0].After)
+	if created["name"] != "Tunai" || logs[0].Before != nil {
+		t.Errorf("create log before = %s after = %v, want only the new row", logs[0].Before, created)
+	}
+
+	before, after := decode(t, logs[1].Before), decode(t, logs[1].After)
+	if before["name"] != "Tunai" || after["name"] != "Dompet Harian" {
+		t.Errorf("update log name = %v -> %v, want Tunai -> Dompet Harian", before["name"], after["name"])
+	}
+	if _, ok := after["balance"]; ok {
+		t.Errorf("update log %v contains an unchanged column", after)
+	}
+	if _, ok := after["household_id"]; !ok {
+		t.Errorf("update log %v is missing household_id", after)
+	}
+
+	deleted := decode(t, logs[2].Before)
+	if deleted["name"] != "Dompet Harian" || logs[2].After != nil {
+		t.Errorf("delete log before = %v after = %s, want only the old row", deleted, logs[2].After)
+	}
+}
+
+func TestAuditBatchUpdate(t *testing.T) {
+	db := openAuditedDB(t)
+	categories := []models.Category{
+		{UserID: 1, HouseholdID: 1, Name: "Makan", Type: "expense"},
+		{UserID: 1, HouseholdID: 1, Name: "Transport", Type: "expense"},
+		{UserID: 1, HouseholdID: 2, Name: "Gaji", Type: "income"},
+	}
+	if err := db.Create(&categories).Error; err != nil {
+		t.Fatalf("create categories: %v", err)
+	}
+	if got := len(auditLogs(t, db, models.AuditEntityCategory)); got != 3 {
+		t.Fatalf("batch create wrote %d logs, want 3", got)
+	}
+
+	if err := db.Model(&models.Category{}).Where("household_id = ?", 1).Update("sort_order", 5).Error; err != nil {
+		t.Fatalf("batch update: %v", err)
+	}
+	var updated []uint
+	for _, log := range auditLogs(t, db, models.AuditEntityCategory) {
+		if log.Action == models.AuditUpdate {
+			updated = append(updated, log.EntityID)
+		}
+		if log.UserID != nil {
+			t.Errorf("log without an actor context has user %d", *log.UserID)
+		}
+	}
+	if len(updated) != 2 || updated[0] != categories[0].ID || updated[1] != categories[1].ID {
+		t.Errorf("update logs for categories %v, want %d and %d", updated, categories[0].ID, categories[1].ID)
+	}
+}
+
+func TestAuditSkipsUnauditedTablesAndSecrets(t *testing.T) {
+	db := openAuditedDB(t)
+
+	if err := db.Create(&models.Tag{HouseholdID: 1, UserID: 1, Name: "liburan"}).Error; err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	var count int64
+	db.Model(&models.AuditLog{}).Count(&count)
+	if count != 0 {
+		t.Errorf("creating a tag wrote %d audit logs, want 0", count)
+	}
+
+	user := models.User{Name: "Ani", Email: "ani@example.com", PasswordHash: "rahasia"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := db.Model(&user).Updates(map[string]interface{}{"password_hash": "baru", "name": "Ani S"}).Error; err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	for _, log := range auditLogs(t, db, models.AuditEntityProfile) {
+		for _, row := range []map[string]interface{}{decode(t, log.Before), decode(t, log.After)} {
+			if _, ok := row["password_hash"]; ok {
+				t.Errorf("%s log contains password_hash", log.Action)
+			}
+		}
+	}
+}
+
+func TestAuditRollsBackWithChange(t *testing.T) {
+	db := openAuditedDB(t)
+	errAbort := errors.New("abort")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.TransactionRule{UserID: 1, HouseholdID: 1, Name: "Kopi", DescriptionContains: "kopi"}).Error; err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("transaction error = %v, want errAbort", err)
+	}
+	if logs := auditLogs(t, db, models.AuditEntityRule); len(logs) != 0 {
+		t.Errorf("rolled back rule left %d audit logs", len(logs))
+	}
+
+	if err := db.Create(&models.TransactionRule{UserID: 1, HouseholdID: 1, Name: "Kopi", DescriptionContains: "kopi"}).Error; err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+	logs := auditLogs(t, db, models.AuditEntityRule)
+	if len(logs) != 1 {
+		t.Fatalf("got %d rule audit logs, want 1", len(logs))
+	}
+	if err := db.Model(&logs[0]).Update("ip", "1.2.3.4").Error; !errors.Is(err, models.ErrAuditLogAppendOnly) {
+		t.Errorf("updating an audit log error = %v, want ErrAuditLogAppendOnly", err)
+	}
+}
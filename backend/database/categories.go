@@ -0,0 +1,53 @@
+package database
+
+import (
+	"dompet/backend/models"
+	"dompet/backend/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SeedDefaultCategories menambahkan kategori bawaan yang belum ada di
+// household, dengan nama dalam bahasa lang. Kategori dianggap sudah ada bila
+// kunci bawaannya sudah dipakai, atau ada kategori bertipe sama dengan nama
+// yang sama, sehingga aman dipanggil berulang kali. Kategori di tempat sampah
+// ikut diperhitungkan agar tidak muncul kembaran saat dipulihkan.
+func SeedDefaultCategories(db *gorm.DB, householdID, userID uint, lang string) ([]models.Category, error) {
+	var existing []models.Category
+	if err := db.Unscoped().Where("household_id = ?", householdID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	names := map[string]bool{}
+	for _, category := range existing {
+		if category.DefaultKey != "" {
+			keys[category.DefaultKey] = true
+		}
+		names[category.Type+":"+strings.ToLower(strings.TrimSpace(category.Name))] = true
+	}
+
+	added := []models.Category{}
+	for _, def := range utils.DefaultCategories() {
+		name := def.Name(lang)
+		if keys[def.Key] || names[def.Type+":"+strings.ToLower(name)] {
+			continue
+		}
+		added = append(added, models.Category{
+			UserID:      userID,
+			HouseholdID: householdID,
+			Name:        name,
+			Type:        def.Type,
+			DefaultKey:  def.Key,
+		})
+	}
+
+	if len(added) == 0 {
+		return added, nil
+	}
+	if err := db.Create(&added).Error; err != nil {
+		return nil, err
+	}
+	return added, nil
+}
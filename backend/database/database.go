@@ -30,5 +30,15 @@ func ConnectDB() {
 		log.Fatal("Failed to connect to database!")
 	}
 
+	// Jalankan migrasi skema dan pengisian data lama sebelum server melayani request
+	if err := Migrate(DB); err != nil {
+		log.Fatal("Failed to migrate database: ", err)
+	}
+
+	// Catat setiap perubahan dompet, kategori, transaksi dan profil
+	if err := RegisterAuditCallbacks(DB); err != nil {
+		log.Fatal("Failed to register audit callbacks!")
+	}
+
 	fmt.Println("Database connection successful!")
 }
@@ -0,0 +1,95 @@
+// Package dbtest menyediakan database SQLite di memori untuk pengujian yang
+// butuh database sungguhan. Skema dibuat dengan AutoMigrate dari model,
+// bukan dari file migrasi yang khusus PostgreSQL.
+package dbtest
+
+import (
+	"database/sql"
+	"dompet/backend/models"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// driverName adalah driver SQLite yang sudah ditambah fungsi PostgreSQL yang
+// dipakai query aplikasi
+const driverName = "sqlite3_dompet"
+
+var registerOnce sync.Once
+
+// allModels adalah semua tabel yang dibuat untuk setiap database uji
+var allModels = []interface{}{
+	&models.User{}, &models.Household{}, &models.HouseholdMember{},
+	&models.Wallet{}, &models.WalletMember{}, &models.WalletInvitation{},
+	&models.Category{}, &models.Tag{}, &models.Transaction{}, &models.TransactionSplit{},
+	&models.Account{}, &models.JournalEntry{}, &models.Posting{},
+	&models.Attachment{}, &models.TransactionRule{}, &models.Reconciliation{},
+	&models.CategoryDocStat{}, &models.CategoryTokenStat{}, &models.DuplicateDismissal{},
+	&models.IdempotencyKey{}, &models.AuditLog{}, &models.SavingsGoal{}, &models.Debt{},
+	&models.BillSplit{}, &models.BillParticipant{}, &models.BillSettlement{},
+}
+
+func registerDriver() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("greatest", func(a, b int64) int64 {
+				if a > b {
+					return a
+				}
+				return b
+			}, true)
+		},
+	})
+}
+
+// Open membuat database SQLite baru di memori lengkap dengan semua tabel.
+// Database ditutup otomatis saat test selesai.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	registerOnce.Do(registerDriver)
+
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: driverName, DSN: ":memory:"}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	// Setiap koneksi :memory: adalah database terpisah
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, model := range allModels {
+		if err := useTextForEnums(db, model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+	}
+	if err := db.AutoMigrate(allModels...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// useTextForEnums mengganti kolom bertipe enum(...) menjadi text, karena
+// SQLite tidak mengenal tipe enum
+func useTextForEnums(db *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, field := range stmt.Schema.Fields {
+		if strings.HasPrefix(strings.ToLower(string(field.DataType)), "enum(") {
+			field.DataType = schema.String
+		}
+	}
+	return nil
+}
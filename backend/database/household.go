@@ -0,0 +1,44 @@
+package database
+
+import (
+	"dompet/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnsurePersonalHousehold mengembalikan household pribadi user, dan
+// membuatnya bila belum ada. Indeks unik idx_households_personal menjamin
+// hanya satu household pribadi per user walaupun dipanggil bersamaan. Data
+// lama tanpa household sudah dipindahkan oleh migrasi 0008_household_backfill.
+func EnsurePersonalHousehold(db *gorm.DB, user models.User) (models.Household, error) {
+	var household models.Household
+	err := db.Transaction(func(tx *gorm.DB) error {
+		created := models.Household{Name: "Pribadi", CreatedByID: user.ID, Personal: true}
+		result := tx.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "created_by_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "personal"}}},
+			DoNothing:   true,
+		}).Create(&created)
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := tx.Where("created_by_id = ? AND personal", user.ID).First(&household).Error; err != nil {
+			return err
+		}
+
+		member := models.HouseholdMember{HouseholdID: household.ID, UserID: user.ID, Role: models.RoleOwner}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "household_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).Create(&member).Error
+	})
+	return household, err
+}
+
+// PersonalHousehold mengembalikan household pribadi user tanpa membuatnya
+func PersonalHousehold(db *gorm.DB, userID uint) (models.Household, error) {
+	var household models.Household
+	err := db.Where("created_by_id = ? AND personal", userID).First(&household).Error
+	return household, err
+}
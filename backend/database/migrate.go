@@ -0,0 +1,92 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles berisi file SQL di folder migrations. Nama file diawali
+// nomor urut (mis. 0002_ledger.sql) dan tidak boleh diubah setelah dirilis.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// schemaMigration mencatat migrasi yang sudah dijalankan
+type schemaMigration struct {
+	Version   string `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time
+}
+
+// Migrate menjalankan migrasi yang belum pernah dijalankan, berurutan
+// menurut nama file. Setiap file dijalankan dalam satu database transaction
+// sehingga migrasi yang gagal tidak meninggalkan skema setengah jadi.
+func Migrate(db *gorm.DB) error {
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`).Error; err != nil {
+		return err
+	}
+
+	var applied []string
+	if err := db.Model(&schemaMigration{}).Pluck("version", &applied).Error; err != nil {
+		return err
+	}
+	done := map[string]bool{}
+	for _, version := range applied {
+		done[version] = true
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+		if done[version] {
+			continue
+		}
+		content, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range splitStatements(string(content)) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{Version: version, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// splitStatements memecah isi file migrasi menjadi statement SQL. Setiap
+// statement diakhiri titik koma di akhir baris.
+func splitStatements(content string) []string {
+	var statements []string
+	for _, statement := range strings.Split(content, ";\n") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+				lines = append(lines, line)
+			}
+		}
+		if statement := strings.TrimSpace(strings.Join(lines, "\n")); statement != "" {
+			statements = append(statements, strings.TrimSuffix(statement, ";"))
+		}
+	}
+	return statements
+}
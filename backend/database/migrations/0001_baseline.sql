@@ -0,0 +1,44 @@
+-- Skema awal aplikasi. Database yang sudah berjalan sebelum migrasi ada
+-- sudah memiliki tabel-tabel ini, sehingga semuanya memakai IF NOT EXISTS.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                BIGSERIAL PRIMARY KEY,
+    name              TEXT NOT NULL,
+    email             TEXT NOT NULL UNIQUE,
+    profile_image_url TEXT,
+    password_hash     TEXT NOT NULL,
+    currency          TEXT DEFAULT 'IDR',
+    timezone          TEXT DEFAULT 'Asia/Jakarta',
+    created_at        TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS wallets (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL REFERENCES users (id),
+    name       TEXT NOT NULL,
+    bank_name  VARCHAR(50),
+    currency   VARCHAR(5) NOT NULL,
+    balance    DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL REFERENCES users (id),
+    name       TEXT NOT NULL,
+    type       VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense')),
+    created_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT NOT NULL REFERENCES users (id),
+    wallet_id        BIGINT NOT NULL,
+    category_id      BIGINT NOT NULL,
+    amount           DECIMAL(15,2) NOT NULL,
+    type             VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense')),
+    description      TEXT,
+    transaction_date DATE NOT NULL,
+    created_at       TIMESTAMPTZ
+);
@@ -0,0 +1,41 @@
+-- Buku besar double-entry di balik transaksi, dan refund atas pengeluaran
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    name         TEXT NOT NULL,
+    type         VARCHAR(20) NOT NULL CHECK (type IN ('asset', 'liability', 'equity', 'income', 'expense')),
+    code         VARCHAR(50),
+    wallet_id    BIGINT,
+    category_id  BIGINT,
+    created_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_wallet_id ON accounts (wallet_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_category_id ON accounts (category_id);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL,
+    transaction_id  BIGINT,
+    kind            VARCHAR(20) NOT NULL CHECK (kind IN ('opening', 'transaction', 'transfer', 'adjustment')),
+    description     TEXT,
+    entry_date      DATE NOT NULL,
+    created_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction_id ON journal_entries (transaction_id);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id               BIGSERIAL PRIMARY KEY,
+    journal_entry_id BIGINT NOT NULL,
+    account_id       BIGINT NOT NULL,
+    debit            DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    credit           DECIMAL(15,2) NOT NULL DEFAULT 0.00
+);
+CREATE INDEX IF NOT EXISTS idx_postings_journal_entry_id ON postings (journal_entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_id ON postings (account_id);
+
+-- Refund: pengembalian dana atas pengeluaran, mengurangi beban kategorinya
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund_of_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_transactions_refund_of_id ON transactions (refund_of_id);
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check CHECK (type IN ('income', 'expense', 'refund'));
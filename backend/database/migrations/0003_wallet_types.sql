@@ -0,0 +1,11 @@
+-- Jenis dompet beserta pengaturan kartu kredit, pinjaman, dan dompet
+-- digital. Dompet lama dianggap tunai.
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'cash'
+    CHECK (type IN ('cash', 'bank', 'ewallet', 'credit_card', 'loan', 'investment'));
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(15,2);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS statement_day INTEGER;
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS due_day INTEGER;
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS principal DECIMAL(15,2);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS interest_rate DECIMAL(6,3);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS balance_cap DECIMAL(15,2);
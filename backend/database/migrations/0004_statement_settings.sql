@@ -0,0 +1,5 @@
+-- Pembayaran minimum kartu kredit per dompet. NULL berarti memakai batas
+-- bawah bawaan mata uang dompet (Rp50.000 untuk IDR, tanpa batas bawah
+-- untuk mata uang lain).
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS minimum_payment_floor DECIMAL(15,2);
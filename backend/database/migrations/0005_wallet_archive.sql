@@ -0,0 +1,3 @@
+-- Dompet yang diarsipkan disembunyikan tanpa menghapus riwayatnya
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
@@ -0,0 +1,24 @@
+-- Dompet bersama: anggota dompet beserta perannya dan undangan yang belum
+-- dijawab
+
+CREATE TABLE IF NOT EXISTS wallet_members (
+    id         BIGSERIAL PRIMARY KEY,
+    wallet_id  BIGINT NOT NULL,
+    user_id    BIGINT NOT NULL,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_member ON wallet_members (wallet_id, user_id);
+
+CREATE TABLE IF NOT EXISTS wallet_invitations (
+    id            BIGSERIAL PRIMARY KEY,
+    wallet_id     BIGINT NOT NULL,
+    email         TEXT NOT NULL,
+    role          VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    invited_by_id BIGINT NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    created_at    TIMESTAMPTZ,
+    responded_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_wallet_invitations_wallet_id ON wallet_invitations (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_invitations_email ON wallet_invitations (email);
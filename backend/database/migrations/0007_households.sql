@@ -0,0 +1,36 @@
+-- Household (workspace) yang memiliki dompet, kategori, transaksi, dan buku
+-- besarnya. Kolom household_id ditambahkan nullable di sini, lalu diisi dan
+-- dijadikan NOT NULL oleh 0008_household_backfill.
+
+CREATE TABLE IF NOT EXISTS households (
+    id            BIGSERIAL PRIMARY KEY,
+    name          TEXT NOT NULL,
+    created_by_id BIGINT NOT NULL,
+    personal      BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ
+);
+ALTER TABLE households ADD COLUMN IF NOT EXISTS personal BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS household_members (
+    id           BIGSERIAL PRIMARY KEY,
+    household_id BIGINT NOT NULL,
+    user_id      BIGINT NOT NULL,
+    role         VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_household_member ON household_members (household_id, user_id);
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS household_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_wallets_household_id ON wallets (household_id);
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS household_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_categories_household_id ON categories (household_id);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS household_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_transactions_household_id ON transactions (household_id);
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS household_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_accounts_household_id ON accounts (household_id);
+
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS household_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_journal_entries_household_id ON journal_entries (household_id);
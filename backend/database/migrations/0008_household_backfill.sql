@@ -0,0 +1,73 @@
+-- Mengisi data lama yang dibuat sebelum household dan buku besar ada, lalu
+-- mengunci kolom household_id menjadi NOT NULL.
+
+-- Household pribadi adalah household pertama yang dibuat tiap user, dan
+-- setiap user hanya boleh punya satu
+UPDATE households SET personal = TRUE
+WHERE id IN (SELECT MIN(id) FROM households GROUP BY created_by_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_households_personal ON households (created_by_id) WHERE personal;
+
+INSERT INTO households (name, created_by_id, personal, created_at)
+SELECT 'Pribadi', u.id, TRUE, NOW() FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM households h WHERE h.created_by_id = u.id AND h.personal);
+
+INSERT INTO household_members (household_id, user_id, role, created_at)
+SELECT h.id, h.created_by_id, 'owner', NOW() FROM households h WHERE h.personal
+ON CONFLICT (household_id, user_id) DO NOTHING;
+
+-- Data lama masuk ke household pribadi pembuatnya
+UPDATE wallets t SET household_id = h.id FROM households h
+WHERE h.personal AND h.created_by_id = t.user_id AND (t.household_id IS NULL OR t.household_id = 0);
+UPDATE categories t SET household_id = h.id FROM households h
+WHERE h.personal AND h.created_by_id = t.user_id AND (t.household_id IS NULL OR t.household_id = 0);
+UPDATE accounts t SET household_id = h.id FROM households h
+WHERE h.personal AND h.created_by_id = t.user_id AND (t.household_id IS NULL OR t.household_id = 0);
+UPDATE journal_entries t SET household_id = h.id FROM households h
+WHERE h.personal AND h.created_by_id = t.user_id AND (t.household_id IS NULL OR t.household_id = 0);
+
+-- Transaksi mengikuti household dompetnya, termasuk yang dicatat anggota lain
+UPDATE transactions t SET household_id = w.household_id FROM wallets w
+WHERE w.id = t.wallet_id AND (t.household_id IS NULL OR t.household_id = 0);
+
+ALTER TABLE wallets ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE accounts ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE journal_entries ALTER COLUMN household_id SET NOT NULL;
+
+-- Dompet lama belum punya akun buku besar. Saldonya (yang sudah mencakup
+-- semua transaksi lama) dibukukan sebagai saldo awal tanpa mengubah saldo
+-- dompetnya.
+CREATE TEMP TABLE legacy_wallets ON COMMIT DROP AS
+SELECT w.id, w.user_id, w.household_id, w.name, w.balance, COALESCE(w.created_at, NOW()) AS created_at,
+       nextval(pg_get_serial_sequence('journal_entries', 'id')) AS journal_id
+FROM wallets w
+WHERE NOT EXISTS (SELECT 1 FROM accounts a WHERE a.wallet_id = w.id);
+
+INSERT INTO accounts (user_id, household_id, name, type, wallet_id, created_at)
+SELECT w.user_id, w.household_id, w.name,
+       CASE WHEN w.type IN ('credit_card', 'loan') THEN 'liability' ELSE 'asset' END, w.id, NOW()
+FROM wallets w JOIN legacy_wallets l ON l.id = w.id;
+
+INSERT INTO accounts (user_id, household_id, name, type, code, created_at)
+SELECT DISTINCT ON (l.household_id) l.user_id, l.household_id, 'Saldo Awal', 'equity', 'opening_balance', NOW()
+FROM legacy_wallets l
+WHERE l.balance <> 0
+  AND NOT EXISTS (SELECT 1 FROM accounts a WHERE a.household_id = l.household_id AND a.code = 'opening_balance')
+ORDER BY l.household_id, l.id;
+
+INSERT INTO journal_entries (id, user_id, household_id, kind, description, entry_date, created_at)
+SELECT l.journal_id, l.user_id, l.household_id, 'opening', 'Saldo awal ' || l.name, l.created_at::date, NOW()
+FROM legacy_wallets l WHERE l.balance <> 0;
+
+INSERT INTO postings (journal_entry_id, account_id, debit, credit)
+SELECT l.journal_id, a.id, GREATEST(l.balance, 0), GREATEST(-l.balance, 0)
+FROM legacy_wallets l JOIN accounts a ON a.wallet_id = l.id
+WHERE l.balance <> 0;
+
+INSERT INTO postings (journal_entry_id, account_id, debit, credit)
+SELECT l.journal_id, a.id, GREATEST(-l.balance, 0), GREATEST(l.balance, 0)
+FROM legacy_wallets l
+JOIN (SELECT household_id, MIN(id) AS id FROM accounts WHERE code = 'opening_balance' GROUP BY household_id) a
+  ON a.household_id = l.household_id
+WHERE l.balance <> 0;
@@ -0,0 +1,4 @@
+-- Subkategori: kategori boleh punya satu induk
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
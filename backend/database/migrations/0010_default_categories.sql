@@ -0,0 +1,6 @@
+-- Bahasa user untuk kategori bawaan, dan penanda kategori bawaan agar tidak
+-- di-seed dua kali
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(10) DEFAULT 'id';
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS default_key VARCHAR(50);
+CREATE INDEX IF NOT EXISTS idx_categories_default_key ON categories (default_key);
@@ -0,0 +1,6 @@
+-- Tampilan kategori: ikon, warna, urutan, dan arsip
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS icon VARCHAR(50);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS color VARCHAR(7);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS sort_order INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
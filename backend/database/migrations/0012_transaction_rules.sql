@@ -0,0 +1,20 @@
+-- Aturan kategorisasi otomatis transaksi
+
+CREATE TABLE IF NOT EXISTS transaction_rules (
+    id                   BIGSERIAL PRIMARY KEY,
+    user_id              BIGINT NOT NULL,
+    household_id         BIGINT NOT NULL,
+    name                 TEXT NOT NULL,
+    priority             INTEGER NOT NULL DEFAULT 0,
+    enabled              BOOLEAN NOT NULL DEFAULT TRUE,
+    description_contains TEXT,
+    description_regex    TEXT,
+    min_amount           DECIMAL(15,2),
+    max_amount           DECIMAL(15,2),
+    wallet_id            BIGINT,
+    set_category_id      BIGINT,
+    rename_description   TEXT,
+    created_at           TIMESTAMPTZ,
+    updated_at           TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_rules_household_id ON transaction_rules (household_id);
@@ -0,0 +1,19 @@
+-- Statistik naive Bayes per user untuk saran kategori: jumlah transaksi dan
+-- jumlah token per kategori
+
+CREATE TABLE IF NOT EXISTS category_doc_stats (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    category_id BIGINT NOT NULL,
+    count       INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_doc_stat ON category_doc_stats (user_id, category_id);
+
+CREATE TABLE IF NOT EXISTS category_token_stats (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    category_id BIGINT NOT NULL,
+    token       VARCHAR(100) NOT NULL,
+    count       INTEGER NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_token_stat ON category_token_stats (user_id, category_id, token);
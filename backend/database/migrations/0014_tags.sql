@@ -0,0 +1,20 @@
+-- Tag sebagai dimensi many-to-many pada transaksi, termasuk aksi aturan
+-- yang menambahkan tag
+
+CREATE TABLE IF NOT EXISTS tags (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    household_id BIGINT NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    color        VARCHAR(7),
+    created_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_household_tag ON tags (household_id, name);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id BIGINT NOT NULL,
+    tag_id         BIGINT NOT NULL,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+ALTER TABLE transaction_rules ADD COLUMN IF NOT EXISTS add_tag_id BIGINT;
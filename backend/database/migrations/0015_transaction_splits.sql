@@ -0,0 +1,10 @@
+-- Rincian transaksi yang dipecah ke beberapa kategori
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id             BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL,
+    category_id    BIGINT NOT NULL,
+    amount         DECIMAL(15,2) NOT NULL,
+    description    TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction_id ON transaction_splits (transaction_id);
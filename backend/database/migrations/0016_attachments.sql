@@ -0,0 +1,15 @@
+-- Lampiran (struk, nota) pada transaksi. Filenya disimpan di blob store;
+-- tabel ini hanya menyimpan key-nya.
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id             BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL,
+    user_id        BIGINT NOT NULL,
+    file_name      TEXT NOT NULL,
+    content_type   VARCHAR(100) NOT NULL,
+    size           BIGINT NOT NULL,
+    storage_key    TEXT NOT NULL,
+    thumbnail_key  TEXT,
+    created_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_transaction_id ON attachments (transaction_id);
@@ -0,0 +1,19 @@
+-- Status transaksi dan rekonsiliasi dompet dengan saldo rekening koran.
+-- Transaksi lama dianggap cleared.
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'cleared'
+    CHECK (status IN ('pending', 'cleared', 'reconciled'));
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reconciliation_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_transactions_reconciliation_id ON transactions (reconciliation_id);
+
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id                BIGSERIAL PRIMARY KEY,
+    wallet_id         BIGINT NOT NULL,
+    user_id           BIGINT NOT NULL,
+    statement_date    DATE NOT NULL,
+    statement_balance DECIMAL(15,2) NOT NULL,
+    status            VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+    created_at        TIMESTAMPTZ,
+    completed_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_reconciliations_wallet_id ON reconciliations (wallet_id);
@@ -0,0 +1,11 @@
+-- Pasangan transaksi yang sudah ditandai bukan duplikat
+
+CREATE TABLE IF NOT EXISTS duplicate_dismissals (
+    id                   BIGSERIAL PRIMARY KEY,
+    transaction_id       BIGINT NOT NULL,
+    other_transaction_id BIGINT NOT NULL,
+    user_id              BIGINT NOT NULL,
+    created_at           TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_duplicate_pair ON duplicate_dismissals (transaction_id, other_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_dismissals_other_transaction_id ON duplicate_dismissals (other_transaction_id);
@@ -0,0 +1,17 @@
+-- Kunci idempotensi beserta respons yang diputar ulang (status, header,
+-- dan body) untuk request yang diulang dengan kunci yang sama
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    completed    BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code  INTEGER,
+    content_type VARCHAR(100),
+    headers      JSON,
+    body         TEXT,
+    created_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_idempotency_key ON idempotency_keys (user_id, key);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
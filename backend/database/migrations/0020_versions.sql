@@ -0,0 +1,5 @@
+-- Nomor versi untuk optimistic concurrency (ETag/If-Match). Baris lama
+-- mulai dari versi 1.
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
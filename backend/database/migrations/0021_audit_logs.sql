@@ -0,0 +1,22 @@
+-- Audit log perubahan data keuangan. Hanya ditambah, tidak pernah diubah.
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT,
+    household_id BIGINT,
+    entity       VARCHAR(20) NOT NULL,
+    entity_id    BIGINT NOT NULL,
+    action       VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before       JSON,
+    after        JSON,
+    ip           VARCHAR(45),
+    request_id   VARCHAR(32),
+    method       VARCHAR(10),
+    path         VARCHAR(255),
+    created_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_household_id ON audit_logs (household_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_logs (entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs (request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
@@ -0,0 +1,16 @@
+-- Tempat sampah: dompet, kategori, dan transaksi dihapus secara soft delete.
+-- Jurnal transfer dan saldo awal dompet yang dibuang menyimpan posting yang
+-- dibatalkan agar bisa dibukukan lagi saat dompetnya dipulihkan.
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS voided_postings JSON;
+CREATE INDEX IF NOT EXISTS idx_journal_entries_deleted_at ON journal_entries (deleted_at);
@@ -0,0 +1,18 @@
+-- Target tabungan; setoran dihitung dari dompet atau tag yang ditautkan
+
+CREATE TABLE IF NOT EXISTS savings_goals (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL,
+    household_id  BIGINT NOT NULL,
+    name          VARCHAR(100) NOT NULL,
+    target_amount DECIMAL(15,2) NOT NULL,
+    currency      VARCHAR(5) NOT NULL,
+    target_date   DATE,
+    wallet_id     BIGINT,
+    tag_id        BIGINT,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_savings_goals_household_id ON savings_goals (household_id);
+CREATE INDEX IF NOT EXISTS idx_savings_goals_wallet_id ON savings_goals (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_savings_goals_tag_id ON savings_goals (tag_id);
@@ -0,0 +1,21 @@
+-- Utang-piutang dan transaksi cicilannya
+
+CREATE TABLE IF NOT EXISTS debts (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    household_id BIGINT NOT NULL,
+    counterparty VARCHAR(100) NOT NULL,
+    direction    VARCHAR(20) NOT NULL CHECK (direction IN ('payable', 'receivable')),
+    principal    DECIMAL(15,2) NOT NULL,
+    currency     VARCHAR(5) NOT NULL,
+    description  TEXT,
+    start_date   DATE NOT NULL,
+    due_date     DATE,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_debts_household_id ON debts (household_id);
+CREATE INDEX IF NOT EXISTS idx_debts_due_date ON debts (due_date);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS debt_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_transactions_debt_id ON transactions (debt_id);
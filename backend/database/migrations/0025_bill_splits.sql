@@ -0,0 +1,47 @@
+-- Tagihan bersama: pembagian tagihan dengan teman dan pelunasannya. Baris
+-- milik user sendiri (nama kosong) menyimpan ID user tersebut.
+
+CREATE TABLE IF NOT EXISTS bill_splits (
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT NOT NULL,
+    household_id     BIGINT NOT NULL,
+    description      TEXT NOT NULL,
+    total_amount     DECIMAL(15,2) NOT NULL,
+    currency         VARCHAR(5) NOT NULL,
+    method           VARCHAR(20) NOT NULL CHECK (method IN ('equal', 'shares', 'exact')),
+    bill_date        DATE NOT NULL,
+    paid_by          VARCHAR(100) NOT NULL DEFAULT '',
+    paid_by_user_id  BIGINT,
+    wallet_id        BIGINT,
+    transaction_id   BIGINT,
+    journal_entry_id BIGINT,
+    created_at       TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_bill_splits_household_id ON bill_splits (household_id);
+
+CREATE TABLE IF NOT EXISTS bill_participants (
+    id            BIGSERIAL PRIMARY KEY,
+    bill_split_id BIGINT NOT NULL,
+    name          VARCHAR(100) NOT NULL DEFAULT '',
+    user_id       BIGINT,
+    shares        DECIMAL(10,2) NOT NULL DEFAULT 0,
+    amount        DECIMAL(15,2) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_bill_participants_bill_split_id ON bill_participants (bill_split_id);
+
+CREATE TABLE IF NOT EXISTS bill_settlements (
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT NOT NULL,
+    household_id     BIGINT NOT NULL,
+    from_name        VARCHAR(100) NOT NULL DEFAULT '',
+    to_name          VARCHAR(100) NOT NULL DEFAULT '',
+    from_user_id     BIGINT,
+    to_user_id       BIGINT,
+    amount           DECIMAL(15,2) NOT NULL,
+    currency         VARCHAR(5) NOT NULL,
+    settled_date     DATE NOT NULL,
+    wallet_id        BIGINT,
+    journal_entry_id BIGINT,
+    created_at       TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_bill_settlements_household_id ON bill_settlements (household_id);
@@ -4,6 +4,7 @@ import (
 	"dompet/backend/controllers"
 	"dompet/backend/database"
 	"dompet/backend/middlewares"
+	"dompet/backend/storage"
 	"log"
 	"os"
 	"time"
@@ -19,51 +20,182 @@ func main() {
 	database.ConnectDB()
 	db := database.DB
 
+	// Penyimpanan file lampiran: disk lokal atau S3-compatible (lihat BLOB_STORE)
+	blobs, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialise blob storage: ", err)
+	}
+
+	// Isi tempat sampah yang melewati TRASH_RETENTION_DAYS dihapus permanen
+	controllers.StartTrashPurger(db, blobs)
+
 	router := gin.Default()
 
 	router.Use(cors.New(cors.Config{
         AllowOrigins:     []string{"http://localhost:3000", "https://aturuang-zeta.vercel.app", "https://aturuang.reftitoindi.my.id"},
         AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
+        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Household-ID", "Idempotency-Key", "If-Match"},
+        ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed", "ETag", "X-Request-ID"},
         AllowCredentials: true,
         MaxAge:           12 * time.Hour,
     }))
 	
 	router.Use(func(c *gin.Context) {
 		c.Set("db", db)
+		c.Set("blobs", blobs)
 		c.Next()
 	})
 
 	authRoutes := router.Group("/auth")
+	authRoutes.Use(middlewares.AuditMiddleware())
 	{
 		authRoutes.POST("/register", controllers.Register)
 		authRoutes.POST("/login", controllers.Login)
 	}
 
+	// Avatar disajikan tanpa autentikasi agar bisa dipakai di tag <img>
+	router.GET("/avatars/*path", controllers.ServeAvatar)
+
 	apiRoutes := router.Group("/api")
-	apiRoutes.Use(middlewares.AuthMiddleware())
+	// Idempotency-Key mencegah retry dari klien mencatat transaksi dua kali
+	apiRoutes.Use(middlewares.AuthMiddleware(), middlewares.AuditMiddleware(), middlewares.HouseholdMiddleware(), middlewares.IdempotencyMiddleware())
 	{
+		// Households (workspace). Household aktif dipilih lewat header X-Household-ID
+		apiRoutes.GET("/households", controllers.GetMyHouseholds)
+		apiRoutes.POST("/households", controllers.CreateHousehold)
+		apiRoutes.PUT("/households/:id", controllers.UpdateHousehold)
+		apiRoutes.GET("/households/:id/members", controllers.GetHouseholdMembers)
+		apiRoutes.POST("/households/:id/members", controllers.AddHouseholdMember)
+		apiRoutes.PUT("/households/:id/members/:userId", controllers.UpdateHouseholdMember)
+		apiRoutes.DELETE("/households/:id/members/:userId", controllers.RemoveHouseholdMember)
+
 		apiRoutes.GET("/profile", controllers.GetProfile)
 		apiRoutes.PUT("/profile", controllers.UpdateProfile)
 		apiRoutes.PUT("/profile/password", controllers.ChangePassword)
+		apiRoutes.POST("/profile/avatar", controllers.UploadAvatar)
+		apiRoutes.DELETE("/profile/avatar", controllers.DeleteAvatar)
 
 		// Wallets
 		apiRoutes.POST("/wallets", controllers.CreateWallet)
 		apiRoutes.GET("/wallets", controllers.GetAllWallets)
+		apiRoutes.GET("/wallets/net-worth", controllers.GetNetWorth)
 		apiRoutes.GET("/wallets/:id", controllers.GetWalletByID)
 		apiRoutes.PUT("/wallets/:id", controllers.UpdateWallet)
 		apiRoutes.DELETE("/wallets/:id", controllers.DeleteWallet)
+		apiRoutes.GET("/wallets/:id/statements", controllers.GetWalletStatements)
+		apiRoutes.POST("/wallets/:id/archive", controllers.ArchiveWallet)
+		apiRoutes.POST("/wallets/:id/unarchive", controllers.UnarchiveWallet)
+
+		// Reconciliation
+		apiRoutes.POST("/wallets/:id/reconciliations", controllers.StartReconciliation)
+		apiRoutes.GET("/wallets/:id/reconciliations", controllers.GetWalletReconciliations)
+		apiRoutes.GET("/reconciliations/:id", controllers.GetReconciliation)
+		apiRoutes.PUT("/reconciliations/:id/transactions", controllers.ReconcileTransactions)
+		apiRoutes.POST("/reconciliations/:id/complete", controllers.CompleteReconciliation)
+		apiRoutes.DELETE("/reconciliations/:id", controllers.CancelReconciliation)
+
+		// Shared wallets
+		apiRoutes.GET("/wallets/:id/members", controllers.GetWalletMembers)
+		apiRoutes.PUT("/wallets/:id/members/:userId", controllers.UpdateWalletMember)
+		apiRoutes.DELETE("/wallets/:id/members/:userId", controllers.RemoveWalletMember)
+		apiRoutes.POST("/wallets/:id/invitations", controllers.CreateWalletInvitation)
+		apiRoutes.GET("/wallets/:id/invitations", controllers.GetWalletInvitations)
+		apiRoutes.DELETE("/wallets/:id/invitations/:invitationId", controllers.RevokeWalletInvitation)
+		apiRoutes.GET("/invitations", controllers.GetMyInvitations)
+		apiRoutes.POST("/invitations/:id/accept", controllers.AcceptInvitation)
+		apiRoutes.POST("/invitations/:id/decline", controllers.DeclineInvitation)
 
 		// Categories
 		apiRoutes.POST("/categories", controllers.CreateCategory)
 		apiRoutes.GET("/categories", controllers.GetAllCategories)
 		apiRoutes.PUT("/categories/:id", controllers.UpdateCategory)
 		apiRoutes.DELETE("/categories/:id", controllers.DeleteCategory)
+		apiRoutes.POST("/categories/:id/merge", controllers.MergeCategory)
+		apiRoutes.PUT("/categories/reorder", controllers.ReorderCategories)
+		apiRoutes.POST("/categories/restore-defaults", controllers.RestoreDefaultCategories)
+
+		// Tags
+		apiRoutes.GET("/tags", controllers.GetAllTags)
+		apiRoutes.POST("/tags", controllers.CreateTag)
+		apiRoutes.PUT("/tags/:id", controllers.UpdateTag)
+		apiRoutes.DELETE("/tags/:id", controllers.DeleteTag)
+
+		// Rules
+		apiRoutes.GET("/rules", controllers.GetAllRules)
+		apiRoutes.POST("/rules", controllers.CreateRule)
+		apiRoutes.PUT("/rules/:id", controllers.UpdateRule)
+		apiRoutes.DELETE("/rules/:id", controllers.DeleteRule)
+		apiRoutes.POST("/rules/:id/test", controllers.TestRule)
+		apiRoutes.POST("/rules/:id/apply", controllers.ApplyRule)
 
 		// Transactions
 		apiRoutes.POST("/transactions", controllers.CreateTransaction)
+		apiRoutes.GET("/transactions/suggest-category", controllers.SuggestCategory)
 		apiRoutes.GET("/transactions", controllers.GetAllTransactions)
+		apiRoutes.GET("/transactions/duplicates", controllers.GetDuplicateTransactions)
+		apiRoutes.POST("/transactions/duplicates/dismiss", controllers.DismissDuplicates)
+		apiRoutes.GET("/transactions/:id", controllers.GetTransactionByID)
+		apiRoutes.PUT("/transactions/:id", controllers.UpdateTransaction)
+		apiRoutes.DELETE("/transactions/:id", controllers.DeleteTransaction)
+		apiRoutes.PUT("/transactions/:id/status", controllers.UpdateTransactionStatus)
+		apiRoutes.POST("/transactions/:id/unlock", controllers.UnlockTransaction)
+		apiRoutes.POST("/transactions/:id/refund", controllers.RefundTransaction)
+		apiRoutes.POST("/transactions/:id/merge", controllers.MergeDuplicates)
+
+		// Attachments
+		apiRoutes.POST("/transactions/:id/attachments", controllers.UploadAttachment)
+		apiRoutes.GET("/transactions/:id/attachments", controllers.GetTransactionAttachments)
+		apiRoutes.GET("/attachments/:id", controllers.DownloadAttachment)
+		apiRoutes.DELETE("/attachments/:id", controllers.DeleteAttachment)
+
+		// Transfers
+		apiRoutes.POST("/transfers", controllers.CreateTransfer)
+		apiRoutes.DELETE("/transfers/:id", controllers.DeleteTransfer)
+
+		// Ledger (double-entry)
+		apiRoutes.GET("/accounts", controllers.GetAllAccounts)
+		apiRoutes.GET("/journal-entries", controllers.GetJournalEntries)
+		apiRoutes.GET("/ledger/trial-balance", controllers.GetTrialBalance)
+
+		// Savings goals
+		apiRoutes.GET("/goals", controllers.GetAllGoals)
+		apiRoutes.POST("/goals", controllers.CreateGoal)
+		apiRoutes.GET("/goals/:id", controllers.GetGoalByID)
+		apiRoutes.PUT("/goals/:id", controllers.UpdateGoal)
+		apiRoutes.DELETE("/goals/:id", controllers.DeleteGoal)
+		apiRoutes.GET("/goals/:id/progress", controllers.GetGoalProgress)
+		apiRoutes.GET("/goals/:id/contributions", controllers.GetGoalContributions)
+
+		// Debts (hutang/piutang)
+		apiRoutes.GET("/debts", controllers.GetAllDebts)
+		apiRoutes.GET("/debts/overdue", controllers.GetOverdueDebts)
+		apiRoutes.POST("/debts", controllers.CreateDebt)
+		apiRoutes.GET("/debts/:id", controllers.GetDebtByID)
+		apiRoutes.PUT("/debts/:id", controllers.UpdateDebt)
+		apiRoutes.DELETE("/debts/:id", controllers.DeleteDebt)
+
+		// Bill splits
+		apiRoutes.GET("/bill-splits", controllers.GetAllBillSplits)
+		apiRoutes.POST("/bill-splits", controllers.CreateBillSplit)
+		apiRoutes.GET("/bill-splits/balances", controllers.GetBillBalances)
+		apiRoutes.GET("/bill-splits/settlements", controllers.GetBillSettlements)
+		apiRoutes.POST("/bill-splits/settlements", controllers.CreateBillSettlement)
+		apiRoutes.DELETE("/bill-splits/settlements/:id", controllers.DeleteBillSettlement)
+		apiRoutes.GET("/bill-splits/:id", controllers.GetBillSplitByID)
+		apiRoutes.DELETE("/bill-splits/:id", controllers.DeleteBillSplit)
+
+		// Reports
+		apiRoutes.GET("/reports/categories", controllers.GetCategoryReport)
+		apiRoutes.GET("/reports/tags", controllers.GetTagReport)
+
+		// Audit
+		apiRoutes.GET("/audit", controllers.GetAuditLog)
+
+		// Trash
+		apiRoutes.GET("/trash", controllers.GetTrash)
+		apiRoutes.POST("/trash/wallets/:id/restore", controllers.RestoreWallet)
+		apiRoutes.POST("/trash/categories/:id/restore", controllers.RestoreCategory)
+		apiRoutes.POST("/trash/transactions/:id/restore", controllers.RestoreTransaction)
 
 		// Exchange
 		apiRoutes.GET("/exchange-rates", controllers.GetExchangeRates)
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"dompet/backend/database"
+	"dompet/backend/models"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequestIDHeader berisi ID request yang juga dicatat di audit log
+const RequestIDHeader = "X-Request-ID"
+
+// newRequestID membuat ID acak 32 karakter hex
+func newRequestID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// AuditMiddleware menyimpan pelaku (user yang login), IP dan ID request ke
+// context koneksi database, sehingga callback audit bisa mencatat siapa yang
+// mengubah data. Dipasang setelah AuthMiddleware; tanpa login pelakunya
+// dikosongkan.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := database.AuditActor{
+			IP:        c.ClientIP(),
+			RequestID: newRequestID(),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+		}
+		if user, ok := c.Get("currentUser"); ok {
+			if u, ok := user.(models.User); ok {
+				actor.UserID = &u.ID
+			}
+		}
+
+		ctx := database.WithAuditActor(c.Request.Context(), actor)
+		c.Request = c.Request.WithContext(ctx)
+		db := c.MustGet("db").(*gorm.DB)
+		c.Set("db", db.WithContext(ctx))
+		c.Header(RequestIDHeader, actor.RequestID)
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"dompet/backend/database"
+	"dompet/backend/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HouseholdHeader adalah header untuk memilih household (workspace) aktif
+const HouseholdHeader = "X-Household-ID"
+
+// HouseholdMiddleware menentukan household aktif dari header X-Household-ID
+// (atau query household_id) dan memastikan user adalah anggotanya. Tanpa
+// header, household pribadi milik user yang dipakai. Harus dipasang setelah
+// AuthMiddleware.
+func HouseholdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := c.MustGet("db").(*gorm.DB)
+		user := c.MustGet("currentUser").(models.User)
+
+		householdID := c.GetHeader(HouseholdHeader)
+		if householdID == "" {
+			householdID = c.Query("household_id")
+		}
+
+		var household models.Household
+		var member models.HouseholdMember
+		if householdID == "" {
+			// Household pribadi dibuat saat registrasi (atau oleh migrasi
+			// untuk user lama); pembuatan di sini hanya jaring pengaman
+			var err error
+			household, err = database.PersonalHousehold(db, user.ID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				household, err = database.EnsurePersonalHousehold(db, user)
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load household"})
+				return
+			}
+			member.Role = models.RoleOwner
+		} else {
+			if err := db.Where("household_id = ? AND user_id = ?", householdID, user.ID).First(&member).Error; err != nil {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of this household"})
+				return
+			}
+			if err := db.First(&household, member.HouseholdID).Error; err != nil {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+				return
+			}
+		}
+
+		household.Role = member.Role
+		c.Set("currentHousehold", household)
+		c.Next()
+	}
+}
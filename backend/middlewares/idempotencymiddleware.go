@@ -0,0 +1,176 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"dompet/backend/models"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyHeader adalah header kunci idempotensi dari klien
+const IdempotencyHeader = "Idempotency-Key"
+
+// IdempotencyReplayedHeader ditambahkan pada respons yang diputar ulang
+const IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyTTL adalah lama kunci idempotensi disimpan sebelum boleh dipakai ulang
+const idempotencyTTL = 24 * time.Hour
+
+// idempotencyMaxBody adalah ukuran body terbesar yang dibaca untuk dihitung
+// hash-nya: lampiran terbesar (10 MB) ditambah overhead multipart
+const idempotencyMaxBody = 11 << 20
+
+// replayedHeaders adalah header respons yang disimpan dan ikut diputar ulang
+var replayedHeaders = []string{"Content-Type", "ETag", "Location", "Content-Disposition"}
+
+// recordingWriter meneruskan respons ke klien sambil menyalin body-nya
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash meringkas isi request agar kunci yang sama dengan payload
+// berbeda bisa dikenali
+func requestHash(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	io.WriteString(hash, c.GetHeader(HouseholdHeader)+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// claimIdempotencyKey mencoba mendaftarkan kunci untuk request ini. Bila kunci
+// sudah ada, record lamanya dikembalikan dengan claimed bernilai false.
+func claimIdempotencyKey(db *gorm.DB, record *models.IdempotencyKey) (bool, error) {
+	// Kunci yang sudah kedaluwarsa boleh dipakai lagi
+	if err := db.Where("user_id = ? AND key = ? AND created_at < ?", record.UserID, record.Key, time.Now().Add(-idempotencyTTL)).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return false, err
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	if err := db.Where("user_id = ? AND key = ?", record.UserID, record.Key).First(record).Error; err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// IdempotencyMiddleware menjalankan request POST/PUT/DELETE yang membawa
+// header Idempotency-Key paling banyak sekali per user. Respons pertama
+// disimpan dan diputar ulang untuk request berikutnya dengan kunci yang sama;
+// kunci yang dipakai ulang untuk payload berbeda ditolak. Respons 5xx tidak
+// disimpan agar klien bisa mencoba lagi. Harus dipasang setelah
+// AuthMiddleware.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		method := c.Request.Method
+		if key == "" || (method != http.MethodPost && method != http.MethodPut && method != http.MethodDelete) {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		db := c.MustGet("db").(*gorm.DB)
+		user := c.MustGet("currentUser").(models.User)
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, idempotencyMaxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(c, body)
+		record := models.IdempotencyKey{UserID: user.ID, Key: key, RequestHash: hash}
+		claimed, err := claimIdempotencyKey(db, &record)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Idempotency-Key"})
+			return
+		}
+		if !claimed {
+			switch {
+			case record.RequestHash != hash:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key has already been used with a different request"})
+			case !record.Completed:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			default:
+				var headers map[string]string
+				json.Unmarshal(record.Headers, &headers)
+				for name, value := range headers {
+					c.Header(name, value)
+				}
+				c.Header(IdempotencyReplayedHeader, "true")
+				c.Data(record.StatusCode, record.ContentType, []byte(record.Body))
+				c.Abort()
+			}
+			return
+		}
+
+		// Handler yang panic tidak boleh meninggalkan kunci berstatus "masih
+		// diproses", karena retry berikutnya akan selalu ditolak 409
+		defer func() {
+			if r := recover(); r != nil {
+				db.Delete(&record)
+				panic(r)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			db.Delete(&record)
+			return
+		}
+		headers := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		encodedHeaders, _ := json.Marshal(headers)
+		db.Model(&record).Updates(map[string]interface{}{
+			"completed":    true,
+			"status_code":  status,
+			"content_type": writer.Header().Get("Content-Type"),
+			"headers":      encodedHeaders,
+			"body":         writer.body.String(),
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package middlewares
+
+import (
+	"dompet/backend/database/dbtest"
+	"dompet/backend/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// idempotencyServer adalah router uji dengan handler yang menghitung berapa
+// kali ia benar-benar dijalankan
+type idempotencyServer struct {
+	router *gin.Engine
+	db     *gorm.DB
+	calls  int
+}
+
+func newIdempotencyServer(t *testing.T) *idempotencyServer {
+	gin.SetMode(gin.TestMode)
+	s := &idempotencyServer{router: gin.New(), db: dbtest.Open(t)}
+	s.router.Use(gin.RecoveryWithWriter(io.Discard), func(c *gin.Context) {
+		userID, _ := strconv.Atoi(c.GetHeader("X-Test-User"))
+		c.Set("db", s.db)
+		c.Set("currentUser", models.User{ID: uint(userID)})
+	}, IdempotencyMiddleware())
+
+	handler := func(c *gin.Context) {
+		s.calls++
+		status, _ := strconv.Atoi(c.DefaultQuery("status", "201"))
+		c.Header("ETag", `"`+strconv.Itoa(s.calls)+`"`)
+		c.JSON(status, gin.H{"call": s.calls})
+	}
+	s.router.POST("/transactions", handler)
+	s.router.PUT("/transactions", handler)
+	s.router.GET("/transactions", handler)
+	s.router.POST("/panic", func(c *gin.Context) {
+		s.calls++
+		panic("handler gagal")
+	})
+	return s
+}
+
+func (s *idempotencyServer) do(method, target, key, body string, user int) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-Test-User", strconv.Itoa(user))
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	first := s.do(http.MethodPost, "/transactions", "abc", `{"amount":100}`, 1)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want 201", first.Code)
+	}
+	if first.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Errorf("first response is marked as replayed")
+	}
+
+	replay := s.do(http.MethodPost, "/transactions", "abc", `{"amount":100}`, 1)
+	if s.calls != 1 {
+		t.Errorf("handler ran %d times, want 1", s.calls)
+	}
+	if replay.Code != first.Code || replay.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", replay.Code, replay.Body, first.Code, first.Body)
+	}
+	for _, name := range []string{"Content-Type", "ETag"} {
+		if got, want := replay.Header().Get(name), first.Header().Get(name); got != want {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+	if replay.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Errorf("replay is missing the %s header", IdempotencyReplayedHeader)
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type step struct {
+		method, target, key, body string
+		user                      int
+		wantStatus                int
+	}
+	post := func(key, body string, wantStatus int) step {
+		return step{method: http.MethodPost, target: "/transactions", key: key, body: body, user: 1, wantStatus: wantStatus}
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, db *gorm.DB)
+		steps     []step
+		wantCalls int
+	}{
+		{
+			name:      "tanpa kunci selalu dijalankan",
+			steps:     []step{post("", "{}", 201), post("", "{}", 201)},
+			wantCalls: 2,
+		},
+		{
+			name: "GET tidak memakai kunci",
+			steps: []step{
+				{method: http.MethodGet, target: "/transactions", key: "abc", user: 1, wantStatus: 201},
+				{method: http.MethodGet, target: "/transactions", key: "abc", user: 1, wantStatus: 201},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "kunci sama dengan body berbeda ditolak",
+			steps:     []step{post("abc", `{"amount":100}`, 201), post("abc", `{"amount":200}`, 422)},
+			wantCalls: 1,
+		},
+		{
+			name: "kunci sama dengan path berbeda ditolak",
+			steps: []step{
+				post("abc", "{}", 201),
+				{method: http.MethodPost, target: "/transactions?status=201&copy=1", key: "abc", body: "{}", user: 1, wantStatus: 422},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "kunci sama dengan method berbeda ditolak",
+			steps: []step{
+				post("abc", "{}", 201),
+				{method: http.MethodPut, target: "/transactions", key: "abc", body: "{}", user: 1, wantStatus: 422},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "kunci milik user lain tidak saling mengganggu",
+			steps: []step{
+				post("abc", "{}", 201),
+				{method: http.MethodPost, target: "/transactions", key: "abc", body: "{}", user: 2, wantStatus: 201},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "respons 5xx tidak disimpan",
+			steps: []step{
+				{method: http.MethodPost, target: "/transactions?status=503", key: "abc", body: "{}", user: 1, wantStatus: 503},
+				{method: http.MethodPost, target: "/transactions?status=503", key: "abc", body: "{}", user: 1, wantStatus: 503},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "respons 4xx diputar ulang",
+			steps: []step{
+				{method: http.MethodPost, target: "/transactions?status=400", key: "abc", body: "{}", user: 1, wantStatus: 400},
+				{method: http.MethodPost, target: "/transactions?status=400", key: "abc", body: "{}", user: 1, wantStatus: 400},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "handler panic melepas kunci",
+			steps: []step{
+				{method: http.MethodPost, target: "/panic", key: "abc", body: "{}", user: 1, wantStatus: 500},
+				{method: http.MethodPost, target: "/panic", key: "abc", body: "{}", user: 1, wantStatus: 500},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "kunci terlalu panjang",
+			steps:     []step{post(strings.Repeat("k", 256), "{}", 400)},
+			wantCalls: 0,
+		},
+		{
+			name: "request yang masih diproses",
+			setup: func(t *testing.T, db *gorm.DB) {
+				c, _ := gin.CreateTestContext(httptest.NewRecorder())
+				c.Request = httptest.NewRequest(http.MethodPost, "/transactions", nil)
+				record := models.IdempotencyKey{UserID: 1, Key: "abc", RequestHash: requestHash(c, []byte("{}"))}
+				if err := db.Create(&record).Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			steps:     []step{post("abc", "{}", 409)},
+			wantCalls: 0,
+		},
+		{
+			name: "kunci kedaluwarsa boleh dipakai lagi",
+			setup: func(t *testing.T, db *gorm.DB) {
+				record := models.IdempotencyKey{UserID: 1, Key: "abc", RequestHash: "lama", Completed: true, StatusCode: 201}
+				if err := db.Create(&record).Error; err != nil {
+					t.Fatal(err)
+				}
+				db.Model(&record).UpdateColumn("created_at", time.Now().Add(-idempotencyTTL-time.Minute))
+			},
+			steps:     []step{post("abc", `{"amount":100}`, 201)},
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIdempotencyServer(t)
+			if tt.setup != nil {
+				tt.setup(t, s.db)
+			}
+			for i, step := range tt.steps {
+				w := s.do(step.method, step.target, step.key, step.body, step.user)
+				if w.Code != step.wantStatus {
+					t.Errorf("request %d status = %d, want %d (body %s)", i+1, w.Code, step.wantStatus, w.Body)
+				}
+			}
+			if s.calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", s.calls, tt.wantCalls)
+			}
+		})
+	}
+}
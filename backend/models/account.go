@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Jenis akun pada buku besar double-entry
+const (
+	AccountAsset     = "asset"
+	AccountLiability = "liability"
+	AccountEquity    = "equity"
+	AccountIncome    = "income"
+	AccountExpense   = "expense"
+)
+
+// Account struct merepresentasikan tabel 'accounts' (akun buku besar).
+// Setiap dompet dan kategori memiliki satu akun yang terhubung lewat
+// WalletID/CategoryID, sedangkan akun sistem (mis. saldo awal) ditandai
+// dengan Code.
+type Account struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null" json:"user_id"`
+	HouseholdID uint      `gorm:"not null;index" json:"household_id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Type        string    `gorm:"type:enum('asset','liability','equity','income','expense');not null" json:"type"`
+	Code        string    `gorm:"size:50" json:"code,omitempty"`
+	WalletID    *uint     `gorm:"uniqueIndex" json:"wallet_id,omitempty"`
+	CategoryID  *uint     `gorm:"uniqueIndex" json:"category_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
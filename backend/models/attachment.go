@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment adalah file (foto struk atau PDF) yang dilampirkan ke transaksi.
+// Isi file disimpan di blob store, tabel ini hanya menyimpan metadatanya.
+type Attachment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	UserID        uint      `gorm:"not null" json:"user_id"`
+	FileName      string    `gorm:"not null" json:"file_name"`
+	ContentType   string    `gorm:"size:100;not null" json:"content_type"`
+	Size          int64     `gorm:"not null" json:"size"`
+	StorageKey    string    `gorm:"not null" json:"-"`
+	ThumbnailKey  string    `json:"-"`
+	HasThumbnail  bool      `gorm:"-" json:"has_thumbnail"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// AfterFind mengisi HasThumbnail untuk respons API
+func (a *Attachment) AfterFind(tx *gorm.DB) error {
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Entitas yang dicatat di audit log
+const (
+	AuditEntityWallet         = "wallet"
+	AuditEntityCategory       = "category"
+	AuditEntityTransaction    = "transaction"
+	AuditEntityProfile        = "profile"
+	AuditEntityRule           = "rule"
+	AuditEntityReconciliation = "reconciliation"
+)
+
+// Jenis perubahan pada audit log
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// ErrAuditLogAppendOnly dikembalikan bila ada yang mencoba mengubah atau
+// menghapus audit log
+var ErrAuditLogAppendOnly = errors.New("audit log is append-only")
+
+// AuditLog adalah satu perubahan pada data keuangan. Before dan After berisi
+// kolom yang berubah (update) atau seluruh baris (create/delete).
+type AuditLog struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	UserID      *uint           `gorm:"index" json:"user_id"` // Pelaku; kosong untuk proses tanpa login
+	HouseholdID *uint           `gorm:"index" json:"household_id,omitempty"`
+	Entity      string          `gorm:"size:20;not null;index:idx_audit_entity" json:"entity"`
+	EntityID    uint            `gorm:"not null;index:idx_audit_entity" json:"entity_id"`
+	Action      string          `gorm:"type:enum('create','update','delete');not null" json:"action"`
+	Before      json.RawMessage `gorm:"type:json" json:"before,omitempty"`
+	After       json.RawMessage `gorm:"type:json" json:"after,omitempty"`
+	IP          string          `gorm:"size:45" json:"ip,omitempty"`
+	RequestID   string          `gorm:"size:32;index" json:"request_id,omitempty"` // Sama untuk semua perubahan dalam satu request
+	Method      string          `gorm:"size:10" json:"method,omitempty"`
+	Path        string          `gorm:"size:255" json:"path,omitempty"`
+	CreatedAt   time.Time       `gorm:"index" json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"actor,omitempty"`
+}
+
+// BeforeUpdate menolak perubahan audit log
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+// BeforeDelete menolak penghapusan audit log
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
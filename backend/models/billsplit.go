@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Cara pembagian tagihan bersama
+const (
+	SplitEqual  = "equal"  // Dibagi rata
+	SplitShares = "shares" // Dibagi sesuai porsi (mis. 2:1:1)
+	SplitExact  = "exact"  // Nominal tiap peserta ditentukan langsung
+)
+
+// BillSplit adalah tagihan bersama, misalnya makan malam rombongan, yang
+// dibayar satu orang lalu dibagi ke para peserta. Peserta dan pembayar
+// dicatat dengan nama; nama kosong berarti user pembuat tagihan, yang ID-nya
+// disimpan agar bagian tiap anggota household tidak tercampur.
+type BillSplit struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null" json:"user_id"`
+	HouseholdID uint      `gorm:"not null;index" json:"household_id"`
+	Description string    `gorm:"not null" json:"description"`
+	TotalAmount float64   `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	Currency    string    `gorm:"size:5;not null" json:"currency"`
+	Method      string    `gorm:"type:enum('equal','shares','exact');not null" json:"method"`
+	BillDate    time.Time `gorm:"type:date;not null" json:"bill_date"`
+	PaidBy      string    `gorm:"size:100;not null;default:''" json:"paid_by"`
+	// Diisi bila pembayarnya user pembuat tagihan (PaidBy kosong)
+	PaidByUserID *uint `json:"paid_by_user_id,omitempty"`
+	// Bila user sendiri yang membayar, bagiannya dicatat sebagai transaksi
+	// pengeluaran di WalletID
+	WalletID      *uint `json:"wallet_id,omitempty"`
+	TransactionID *uint `json:"transaction_id,omitempty"`
+	// Jurnal talangan untuk peserta lain (bila user yang membayar) atau
+	// bagian user yang ditalangi orang lain
+	JournalEntryID *uint     `json:"journal_entry_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Participants []BillParticipant `gorm:"foreignKey:BillSplitID" json:"participants"`
+}
+
+// BillParticipant adalah bagian satu peserta pada tagihan bersama
+type BillParticipant struct {
+	ID          uint    `gorm:"primaryKey" json:"id"`
+	BillSplitID uint    `gorm:"not null;index" json:"bill_split_id"`
+	Name        string  `gorm:"size:100;not null;default:''" json:"name"`
+	UserID      *uint   `json:"user_id,omitempty"`                                             // Diisi bila pesertanya user pembuat tagihan (Name kosong)
+	Shares      float64 `gorm:"type:decimal(10,2);not null;default:0" json:"shares,omitempty"` // Porsi, untuk pembagian "shares"
+	Amount      float64 `gorm:"type:decimal(15,2);not null" json:"amount"`                     // Nominal yang ditanggung
+}
+
+// BillSettlement adalah pembayaran pelunasan antar peserta tagihan bersama.
+// Bila user sendiri yang membayar atau menerima, uangnya dicatat di WalletID.
+type BillSettlement struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"not null" json:"user_id"`
+	HouseholdID    uint      `gorm:"not null;index" json:"household_id"`
+	FromName       string    `gorm:"size:100;not null;default:''" json:"from"`
+	ToName         string    `gorm:"size:100;not null;default:''" json:"to"`
+	FromUserID     *uint     `json:"from_user_id,omitempty"` // Diisi bila FromName kosong
+	ToUserID       *uint     `json:"to_user_id,omitempty"`   // Diisi bila ToName kosong
+	Amount         float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency       string    `gorm:"size:5;not null" json:"currency"`
+	SettledDate    time.Time `gorm:"type:date;not null" json:"settled_date"`
+	WalletID       *uint     `json:"wallet_id,omitempty"`
+	JournalEntryID *uint     `json:"journal_entry_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
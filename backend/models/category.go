@@ -1,14 +1,28 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Category struct merepresentasikan tabel 'categories'
 type Category struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
-	Name      string    `gorm:"not null" json:"name"`
-	Type      string    `gorm:"type:enum('income','expense');not null" json:"type"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null" json:"user_id"`
+	HouseholdID uint       `gorm:"not null;index" json:"household_id"`
+	ParentID    *uint      `gorm:"index" json:"parent_id"` // Kategori induk, nil untuk kategori utama
+	Name        string     `gorm:"not null" json:"name"`
+	Type        string     `gorm:"type:enum('income','expense');not null" json:"type"`
+	DefaultKey  string     `gorm:"size:50;index" json:"default_key,omitempty"` // Kunci kategori bawaan, kosong untuk kategori buatan user
+	Icon        string     `gorm:"size:50" json:"icon"`                        // Kunci ikon, misalnya "shopping-cart"
+	Color       string     `gorm:"size:7" json:"color"`                        // Warna hex, misalnya "#FF8800"
+	SortOrder   int        `gorm:"not null;default:0" json:"sort_order"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// Kategori yang dihapus masuk tempat sampah dan bisa dipulihkan sampai dibersihkan
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
-	User User `gorm:"foreignKey:UserID" json:"-"`
+	User     User       `gorm:"foreignKey:UserID" json:"-"`
+	Children []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
 }
@@ -0,0 +1,20 @@
+package models
+
+// CategoryDocStat menyimpan jumlah transaksi user per kategori, dipakai
+// sebagai peluang awal model saran kategori
+type CategoryDocStat struct {
+	ID         uint `gorm:"primaryKey" json:"id"`
+	UserID     uint `gorm:"not null;uniqueIndex:idx_category_doc_stat" json:"user_id"`
+	CategoryID uint `gorm:"not null;uniqueIndex:idx_category_doc_stat" json:"category_id"`
+	Count      int  `gorm:"not null;default:0" json:"count"`
+}
+
+// CategoryTokenStat menyimpan berapa kali sebuah token deskripsi muncul pada
+// transaksi user di suatu kategori
+type CategoryTokenStat struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	UserID     uint   `gorm:"not null;uniqueIndex:idx_category_token_stat" json:"user_id"`
+	CategoryID uint   `gorm:"not null;uniqueIndex:idx_category_token_stat" json:"category_id"`
+	Token      string `gorm:"size:100;not null;uniqueIndex:idx_category_token_stat" json:"token"`
+	Count      int    `gorm:"not null;default:0" json:"count"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Arah utang-piutang
+const (
+	DebtPayable    = "payable"    // Hutang: saya berutang ke pihak lain
+	DebtReceivable = "receivable" // Piutang: pihak lain berutang ke saya
+)
+
+// Status utang-piutang
+const (
+	DebtOpen    = "open"
+	DebtSettled = "settled"
+)
+
+// Debt adalah hutang atau piutang dengan teman/keluarga. Cicilannya dicatat
+// sebagai transaksi dompet yang ditautkan lewat Transaction.DebtID:
+// pengeluaran untuk hutang dan pemasukan untuk piutang.
+type Debt struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null" json:"user_id"`
+	HouseholdID  uint       `gorm:"not null;index" json:"household_id"`
+	Counterparty string     `gorm:"size:100;not null" json:"counterparty"` // Nama pemberi/penerima pinjaman
+	Direction    string     `gorm:"type:enum('payable','receivable');not null" json:"direction"`
+	Principal    float64    `gorm:"type:decimal(15,2);not null" json:"principal"`
+	Currency     string     `gorm:"size:5;not null" json:"currency"`
+	Description  string     `json:"description"`
+	StartDate    time.Time  `gorm:"type:date;not null" json:"start_date"`
+	DueDate      *time.Time `gorm:"type:date;index" json:"due_date,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Dihitung saat dibaca, tidak disimpan
+	Repaid      float64 `gorm:"-" json:"repaid"`
+	Outstanding float64 `gorm:"-" json:"outstanding"`
+	Status      string  `gorm:"-" json:"status"`
+	Overdue     bool    `gorm:"-" json:"overdue"`
+
+	Transactions []Transaction `gorm:"foreignKey:DebtID" json:"transactions,omitempty"`
+}
+
+// RepaymentType adalah tipe transaksi yang mengurangi sisa utang-piutang
+func (d Debt) RepaymentType() string {
+	if d.Direction == DebtPayable {
+		return "expense"
+	}
+	return "income"
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// DuplicateDismissal menandai pasangan transaksi yang sudah dinyatakan user
+// bukan duplikat, sehingga tidak dilaporkan lagi oleh pendeteksi duplikat.
+// TransactionID selalu ID yang lebih kecil dari pasangan tersebut.
+type DuplicateDismissal struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	TransactionID      uint      `gorm:"not null;uniqueIndex:idx_duplicate_pair" json:"transaction_id"`
+	OtherTransactionID uint      `gorm:"not null;uniqueIndex:idx_duplicate_pair;index" json:"other_transaction_id"`
+	UserID             uint      `gorm:"not null" json:"user_id"`
+	CreatedAt          time.Time `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Sumber setoran target tabungan
+const (
+	GoalSourceTransfer    = "transfer"
+	GoalSourceTransaction = "transaction"
+)
+
+// SavingsGoal adalah target tabungan household, misalnya "Dana Darurat 6
+// bulan" atau "DP Rumah". Setoran dihitung dari transfer ke dan dari WalletID
+// serta transaksi yang diberi tag TagID.
+type SavingsGoal struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null" json:"user_id"`
+	HouseholdID  uint       `gorm:"not null;index" json:"household_id"`
+	Name         string     `gorm:"size:100;not null" json:"name"`
+	TargetAmount float64    `gorm:"type:decimal(15,2);not null" json:"target_amount"`
+	Currency     string     `gorm:"size:5;not null" json:"currency"`
+	TargetDate   *time.Time `gorm:"type:date" json:"target_date,omitempty"`
+	WalletID     *uint      `gorm:"index" json:"wallet_id"` // Dompet tempat dana target disimpan
+	TagID        *uint      `gorm:"index" json:"tag_id"`    // Tag penanda transaksi setoran
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Wallet *Wallet `gorm:"foreignKey:WalletID" json:"wallet,omitempty"`
+	Tag    *Tag    `gorm:"foreignKey:TagID" json:"tag,omitempty"`
+}
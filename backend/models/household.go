@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Household struct merepresentasikan tabel 'households' (workspace).
+// Dompet, kategori, transaksi dan buku besar dimiliki oleh satu household.
+type Household struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"not null" json:"name"`
+	CreatedByID uint   `gorm:"not null" json:"created_by_id"`
+	// Household pribadi dibuat otomatis untuk setiap user; hanya ada satu per user
+	Personal  bool      `gorm:"not null;default:false" json:"personal"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Peran user yang sedang login, dihitung saat dibaca
+	Role string `gorm:"-" json:"role,omitempty"`
+}
+
+// HouseholdMember struct merepresentasikan tabel 'household_members'.
+// Peran memakai konstanta yang sama dengan anggota dompet bersama.
+type HouseholdMember struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	HouseholdID uint      `gorm:"not null;uniqueIndex:idx_household_member" json:"household_id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_household_member" json:"user_id"`
+	Role        string    `gorm:"type:enum('owner','editor','viewer');not null" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Household Household `gorm:"foreignKey:HouseholdID" json:"-"`
+	User      User      `gorm:"foreignKey:UserID" json:"user"`
+}
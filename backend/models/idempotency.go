@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyKey menyimpan respons pertama dari request yang dikirim dengan
+// header Idempotency-Key, agar request ulang (retry) mendapat respons yang
+// sama tanpa dieksekusi dua kali
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_user_idempotency_key" json:"user_id"`
+	Key         string `gorm:"size:255;not null;uniqueIndex:idx_user_idempotency_key" json:"key"`
+	RequestHash string `gorm:"size:64;not null" json:"-"` // SHA-256 dari method, path, household dan body
+	Completed   bool   `gorm:"not null;default:false" json:"completed"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `gorm:"size:100" json:"content_type"`
+	// Header respons (ETag, Location, dll.) yang ikut diputar ulang
+	Headers   json.RawMessage `gorm:"type:json" json:"-"`
+	Body      string          `gorm:"type:text" json:"-"`
+	CreatedAt time.Time       `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Jenis jurnal
+const (
+	JournalOpening     = "opening"
+	JournalTransaction = "transaction"
+	JournalTransfer    = "transfer"
+	JournalAdjustment  = "adjustment"
+)
+
+// JournalEntry struct merepresentasikan tabel 'journal_entries'.
+// Total debit dan kredit dari Postings selalu sama.
+type JournalEntry struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null" json:"user_id"`
+	HouseholdID   uint      `gorm:"not null;index" json:"household_id"`
+	TransactionID *uint     `gorm:"index" json:"transaction_id,omitempty"`
+	Kind          string    `gorm:"type:enum('opening','transaction','transfer','adjustment');not null" json:"kind"`
+	Description   string    `json:"description"`
+	EntryDate     time.Time `gorm:"type:date;not null" json:"entry_date"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Jurnal transfer dan saldo awal dompet yang dihapus dibatalkan (void):
+	// posting-nya disimpan di VoidedPostings agar bisa dibukukan lagi saat
+	// dompetnya dipulihkan
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
+	VoidedPostings json.RawMessage `gorm:"type:json" json:"-"`
+
+	User     User      `gorm:"foreignKey:UserID" json:"-"`
+	Postings []Posting `gorm:"foreignKey:JournalEntryID" json:"postings"`
+}
+
+// Posting struct merepresentasikan tabel 'postings' (satu baris debit/kredit)
+type Posting struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	JournalEntryID uint    `gorm:"not null;index" json:"journal_entry_id"`
+	AccountID      uint    `gorm:"not null;index" json:"account_id"`
+	Debit          float64 `gorm:"type:decimal(15,2);not null;default:0.00" json:"debit"`
+	Credit         float64 `gorm:"type:decimal(15,2);not null;default:0.00" json:"credit"`
+
+	Account Account `gorm:"foreignKey:AccountID" json:"account"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Status transaksi
+const (
+	TransactionPending    = "pending"    // Belum diproses bank
+	TransactionCleared    = "cleared"    // Sudah diproses bank
+	TransactionReconciled = "reconciled" // Sudah dicocokkan dengan rekening koran dan dikunci
+)
+
+// Status rekonsiliasi
+const (
+	ReconciliationInProgress = "in_progress"
+	ReconciliationCompleted  = "completed"
+)
+
+// Reconciliation adalah pencocokan transaksi dompet dengan saldo akhir
+// rekening koran pada tanggal tertentu
+type Reconciliation struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	WalletID         uint       `gorm:"not null;index" json:"wallet_id"`
+	UserID           uint       `gorm:"not null" json:"user_id"`
+	StatementDate    time.Time  `gorm:"type:date;not null" json:"statement_date"`
+	StatementBalance float64    `gorm:"type:decimal(15,2);not null" json:"statement_balance"`
+	Status           string     `gorm:"type:enum('in_progress','completed');not null;default:'in_progress'" json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+
+	Wallet Wallet `gorm:"foreignKey:WalletID" json:"-"`
+}
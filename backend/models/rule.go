@@ -0,0 +1,81 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransactionRule adalah aturan kategorisasi otomatis. Semua kondisi yang
+// diisi harus terpenuhi agar aturan cocok; kondisi kosong diabaikan.
+type TransactionRule struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	UserID      uint   `gorm:"not null" json:"user_id"`
+	HouseholdID uint   `gorm:"not null;index" json:"household_id"`
+	Name        string `gorm:"not null" json:"name"`
+	Priority    int    `gorm:"not null;default:0" json:"priority"` // Angka kecil dievaluasi lebih dulu
+	Enabled     bool   `gorm:"not null;default:true" json:"enabled"`
+
+	// Kondisi
+	DescriptionContains string   `json:"description_contains"`
+	DescriptionRegex    string   `json:"description_regex"`
+	MinAmount           *float64 `gorm:"type:decimal(15,2)" json:"min_amount"`
+	MaxAmount           *float64 `gorm:"type:decimal(15,2)" json:"max_amount"`
+	WalletID            *uint    `json:"wallet_id"`
+
+	// Aksi
+	SetCategoryID     *uint  `json:"set_category_id"`
+	RenameDescription string `json:"rename_description"`
+	AddTagID          *uint  `json:"add_tag_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SetCategory *Category `gorm:"foreignKey:SetCategoryID" json:"set_category,omitempty"`
+	AddTag      *Tag      `gorm:"foreignKey:AddTagID" json:"add_tag,omitempty"`
+
+	// Regex yang sudah dikompilasi saat aturan dibaca dari database
+	descriptionRegexp *regexp.Regexp
+}
+
+// AfterFind mengompilasi DescriptionRegex sekali per aturan
+func (r *TransactionRule) AfterFind(tx *gorm.DB) error {
+	r.descriptionRegexp = nil
+	if r.DescriptionRegex != "" {
+		r.descriptionRegexp, _ = regexp.Compile(r.DescriptionRegex)
+	}
+	return nil
+}
+
+// Matches memeriksa apakah transaksi dengan deskripsi, nominal, dan dompet
+// tersebut memenuhi semua kondisi aturan
+func (r TransactionRule) Matches(description string, amount float64, walletID uint) bool {
+	if r.DescriptionContains != "" && !strings.Contains(strings.ToLower(description), strings.ToLower(r.DescriptionContains)) {
+		return false
+	}
+	if r.DescriptionRegex != "" {
+		re := r.descriptionRegexp
+		if re == nil || re.String() != r.DescriptionRegex {
+			// Aturan yang belum disimpan (atau regex-nya baru diubah)
+			var err error
+			if re, err = regexp.Compile(r.DescriptionRegex); err != nil {
+				return false
+			}
+		}
+		if !re.MatchString(description) {
+			return false
+		}
+	}
+	if r.MinAmount != nil && amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && amount > *r.MaxAmount {
+		return false
+	}
+	if r.WalletID != nil && *r.WalletID != walletID {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Tag adalah label bebas untuk transaksi (misalnya "liburan-bali-2026"),
+// melengkapi kategori agar satu perjalanan atau proyek bisa dilacak lintas
+// kategori
+type Tag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null" json:"user_id"`
+	HouseholdID uint      `gorm:"not null;uniqueIndex:idx_household_tag" json:"household_id"`
+	Name        string    `gorm:"size:100;not null;uniqueIndex:idx_household_tag" json:"name"`
+	Color       string    `gorm:"size:7" json:"color"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -1,20 +1,46 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Transaction struct merepresentasikan tabel 'transactions'
 type Transaction struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	UserID          uint      `gorm:"not null" json:"user_id"`
-	WalletID        uint      `gorm:"not null" json:"wallet_id"`
-	CategoryID      uint      `gorm:"not null" json:"category_id"`
-	Amount          float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
-	Type            string    `gorm:"type:enum('income','expense');not null" json:"type"`
-	Description     string    `json:"description"`
-	TransactionDate time.Time `gorm:"type:date;not null" json:"transaction_date"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	UserID           uint      `gorm:"not null" json:"user_id"`
+	HouseholdID      uint      `gorm:"not null;index" json:"household_id"`
+	WalletID         uint      `gorm:"not null" json:"wallet_id"`
+	CategoryID       uint      `gorm:"not null" json:"category_id"`
+	Amount           float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Type             string    `gorm:"type:enum('income','expense','refund');not null" json:"type"` // "refund": pengembalian dana atas pengeluaran
+	Description      string    `json:"description"`
+	TransactionDate  time.Time `gorm:"type:date;not null" json:"transaction_date"`
+	Status           string    `gorm:"type:enum('pending','cleared','reconciled');not null;default:'cleared'" json:"status"`
+	ReconciliationID *uint     `gorm:"index" json:"reconciliation_id,omitempty"` // Rekonsiliasi yang mengunci transaksi ini
+	DebtID           *uint     `gorm:"index" json:"debt_id,omitempty"`           // Hutang/piutang yang dicicil atau dicairkan lewat transaksi ini
+	RefundOfID       *uint     `gorm:"index" json:"refund_of_id,omitempty"`      // Pengeluaran yang dikembalikan dananya oleh transaksi ini
+	Version          uint      `gorm:"not null;default:1" json:"version"`        // Naik setiap kali transaksi diubah, dipakai sebagai ETag
+	CreatedAt        time.Time `json:"created_at"`
+	// Transaksi yang dihapus masuk tempat sampah dan bisa dipulihkan sampai dibersihkan
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	User     *User              `gorm:"foreignKey:UserID" json:"created_by,omitempty"` // Anggota yang mencatat transaksi
+	Wallet   Wallet             `gorm:"foreignKey:WalletID" json:"wallet"`             // Sertakan data wallet
+	Category Category           `gorm:"foreignKey:CategoryID" json:"category"`         // Sertakan data kategori
+	Tags     []Tag              `gorm:"many2many:transaction_tags" json:"tags"`
+	Splits   []TransactionSplit `gorm:"foreignKey:TransactionID" json:"splits,omitempty"` // Rincian per kategori, kosong bila tidak dipecah
+}
+
+// TransactionSplit adalah satu baris rincian transaksi yang dipecah ke
+// beberapa kategori. Jumlah semua baris sama dengan nominal transaksi.
+type TransactionSplit struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	TransactionID uint    `gorm:"not null;index" json:"transaction_id"`
+	CategoryID    uint    `gorm:"not null" json:"category_id"`
+	Amount        float64 `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Description   string  `json:"description"`
 
-	User     User     `gorm:"foreignKey:UserID" json:"-"`
-	Wallet   Wallet   `gorm:"foreignKey:WalletID" json:"wallet"`     // Sertakan data wallet
-	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Sertakan data kategori
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"`
 }
@@ -11,5 +11,6 @@ type User struct {
 	PasswordHash    string    `gorm:"not null" json:"-"`
 	Currency        string    `gorm:"default:'IDR'" json:"currency"`
 	Timezone        string    `gorm:"default:'Asia/Jakarta'" json:"timezone"`
+	Language        string    `gorm:"size:10;default:'id'" json:"language"`
 	CreatedAt       time.Time `json:"created_at"`
 }
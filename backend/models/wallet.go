@@ -2,19 +2,70 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Jenis dompet
+const (
+	WalletCash       = "cash"
+	WalletBank       = "bank"
+	WalletEWallet    = "ewallet"
+	WalletCreditCard = "credit_card"
+	WalletLoan       = "loan"
+	WalletInvestment = "investment"
 )
 
-// Wallet struct merepresentasikan tabel 'wallets' di database
+// Wallet struct merepresentasikan tabel 'wallets' di database.
+// Untuk dompet kewajiban (kartu kredit dan pinjaman) Balance bernilai
+// negatif sebesar jumlah yang masih terutang.
 type Wallet struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
-	Name      string    `gorm:"not null" json:"name"`
-	BankName  string    `gorm:"size:50" json:"bank_name,omitempty"`
-	Currency  string    `gorm:"size:5;not null" json:"currency"`
-	Balance   float64   `gorm:"type:decimal(15,2);not null;default:0.00" json:"balance"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null" json:"user_id"`
+	HouseholdID uint      `gorm:"not null;index" json:"household_id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Type        string    `gorm:"type:enum('cash','bank','ewallet','credit_card','loan','investment');not null;default:'cash'" json:"type"`
+	BankName    string    `gorm:"size:50" json:"bank_name,omitempty"`
+	Currency    string    `gorm:"size:5;not null" json:"currency"`
+	Balance     float64   `gorm:"type:decimal(15,2);not null;default:0.00" json:"balance"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     uint      `gorm:"not null;default:1" json:"version"` // Naik setiap kali dompet diubah, dipakai sebagai ETag
+	// Dompet yang diarsipkan disembunyikan dari pilihan, tetapi riwayatnya tetap ada
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Dompet yang dihapus masuk tempat sampah dan bisa dipulihkan sampai dibersihkan
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Kartu kredit
+	CreditLimit  *float64 `gorm:"type:decimal(15,2)" json:"credit_limit,omitempty"`
+	StatementDay *int     `json:"statement_day,omitempty"`
+	DueDay       *int     `json:"due_day,omitempty"`
+	// Batas bawah pembayaran minimum; kosong berarti bawaan mata uang dompet
+	MinimumPaymentFloor *float64 `gorm:"type:decimal(15,2)" json:"minimum_payment_floor,omitempty"`
+	// Pinjaman
+	Principal    *float64 `gorm:"type:decimal(15,2)" json:"principal,omitempty"`
+	InterestRate *float64 `gorm:"type:decimal(6,3)" json:"interest_rate,omitempty"` // persen per tahun
+	// E-wallet (GoPay, OVO, dll.)
+	BalanceCap *float64 `gorm:"type:decimal(15,2)" json:"balance_cap,omitempty"`
+
+	// Dihitung saat dibaca, tidak disimpan
+	AvailableCredit *float64 `gorm:"-" json:"available_credit,omitempty"`
+	Role            string   `gorm:"-" json:"role,omitempty"` // peran user yang sedang login
 
 	// Relasi: Sebuah dompet dimiliki oleh seorang User
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
+
+// IsLiability bernilai true untuk dompet yang merupakan utang
+func (w Wallet) IsLiability() bool {
+	return w.Type == WalletCreditCard || w.Type == WalletLoan
+}
+
+// AfterFind menghitung sisa limit kartu kredit
+func (w *Wallet) AfterFind(tx *gorm.DB) error {
+	if w.Type == WalletCreditCard && w.CreditLimit != nil {
+		available := *w.CreditLimit + w.Balance
+		w.AvailableCredit = &available
+	}
+	return nil
+}
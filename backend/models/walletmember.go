@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Peran anggota dompet bersama
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Status undangan dompet bersama
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// WalletMember struct merepresentasikan tabel 'wallet_members'
+type WalletMember struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	WalletID  uint      `gorm:"not null;uniqueIndex:idx_wallet_member" json:"wallet_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_wallet_member" json:"user_id"`
+	Role      string    `gorm:"type:enum('owner','editor','viewer');not null" json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Wallet Wallet `gorm:"foreignKey:WalletID" json:"-"`
+	User   User   `gorm:"foreignKey:UserID" json:"user"`
+}
+
+// WalletInvitation struct merepresentasikan tabel 'wallet_invitations'.
+// Undangan ditujukan ke email dan muncul untuk user dengan email tersebut.
+type WalletInvitation struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	WalletID    uint       `gorm:"not null;index" json:"wallet_id"`
+	Email       string     `gorm:"not null;index" json:"email"`
+	Role        string     `gorm:"type:enum('editor','viewer');not null" json:"role"`
+	InvitedByID uint       `gorm:"not null" json:"invited_by_id"`
+	Status      string     `gorm:"type:enum('pending','accepted','declined','revoked');not null;default:'pending'" json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+
+	Wallet    Wallet `gorm:"foreignKey:WalletID" json:"wallet"`
+	InvitedBy User   `gorm:"foreignKey:InvitedByID" json:"invited_by"`
+}
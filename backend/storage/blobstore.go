@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNotFound dikembalikan bila blob dengan key tersebut tidak ada
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore adalah penyimpanan file (lampiran, avatar). Key berupa path
+// relatif dengan pemisah "/", misalnya "attachments/1/abc.jpg".
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromEnv membuat BlobStore sesuai env BLOB_STORE: "s3" untuk penyimpanan
+// S3-compatible (S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY,
+// S3_SECRET_KEY), selain itu disk lokal di BLOB_DIR (default "./uploads").
+func NewFromEnv() (BlobStore, error) {
+	if strings.EqualFold(os.Getenv("BLOB_STORE"), "s3") {
+		store := &S3Store{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}
+		if store.Region == "" {
+			store.Region = "us-east-1"
+		}
+		if store.Endpoint == "" || store.Bucket == "" || store.AccessKey == "" || store.SecretKey == "" {
+			return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY must be set when BLOB_STORE=s3")
+		}
+		return store, nil
+	}
+
+	dir := os.Getenv("BLOB_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	return NewLocalStore(dir)
+}
+
+// validKey menolak key kosong, absolut, atau yang keluar dari root
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+var errInvalidKey = errors.New("invalid blob key")
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxTokenRunes sama dengan panjang kolom category_token_stats.token
+const maxTokenRunes = 100
+
+// Tokenize memecah deskripsi transaksi menjadi token huruf kecil. Angka murni
+// (tanggal, nomor invoice) dan token satu huruf dibuang karena tidak
+// menggambarkan kategori. Token dipotong paling banyak maxTokenRunes karakter
+// (bukan byte) agar huruf multi-byte tidak terbelah.
+func Tokenize(description string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := map[string]bool{}
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		runes := []rune(field)
+		if len(runes) > maxTokenRunes {
+			field = string(runes[:maxTokenRunes])
+		}
+		if len(runes) < 2 || isDigits(field) || seen[field] {
+			continue
+		}
+		seen[field] = true
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// AmountToken mengubah nominal menjadi token orde besarnya, sehingga
+// transaksi dengan nominal mirip saling memperkuat
+func AmountToken(amount float64) string {
+	if amount < 1 {
+		return "amount:0"
+	}
+	return "amount:" + strconv.Itoa(int(math.Log10(amount)))
+}
+
+// BayesModel adalah statistik naive Bayes multinomial: jumlah dokumen dan
+// jumlah token per kategori
+type BayesModel struct {
+	DocCounts   map[uint]int
+	TokenCounts map[uint]map[string]int
+}
+
+// Suggestion adalah kategori hasil prediksi beserta peluangnya
+type Suggestion struct {
+	CategoryID uint    `json:"category_id"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Rank menghitung peluang setiap kategori untuk token yang diberikan dengan
+// Laplace smoothing, diurutkan dari yang paling mungkin
+func (m BayesModel) Rank(tokens []string) []Suggestion {
+	vocabulary := map[string]bool{}
+	totals := map[uint]int{}
+	var docs int
+	for categoryID, count := range m.DocCounts {
+		if count <= 0 {
+			continue
+		}
+		docs += count
+		for token, n := range m.TokenCounts[categoryID] {
+			vocabulary[token] = true
+			totals[categoryID] += n
+		}
+	}
+	if docs == 0 {
+		return []Suggestion{}
+	}
+
+	scores := map[uint]float64{}
+	best := math.Inf(-1)
+	for categoryID, count := range m.DocCounts {
+		if count <= 0 {
+			continue
+		}
+		score := math.Log(float64(count) / float64(docs))
+		for _, token := range tokens {
+			n := m.TokenCounts[categoryID][token]
+			score += math.Log(float64(n+1) / float64(totals[categoryID]+len(vocabulary)+1))
+		}
+		scores[categoryID] = score
+		best = math.Max(best, score)
+	}
+
+	// Normalisasi log-probabilitas menjadi peluang (softmax)
+	var sum float64
+	for categoryID, score := range scores {
+		scores[categoryID] = math.Exp(score - best)
+		sum += scores[categoryID]
+	}
+	suggestions := make([]Suggestion, 0, len(scores))
+	for categoryID, score := range scores {
+		suggestions = append(suggestions, Suggestion{CategoryID: categoryID, Confidence: score / sum})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CategoryID < suggestions[j].CategoryID
+	})
+	return suggestions
+}
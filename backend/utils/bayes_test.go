@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        []string
+	}{
+		{
+			name:        "angka murni dan huruf tunggal dibuang",
+			description: "Makan siang di Warung 123 a",
+			want:        []string{"makan", "siang", "di", "warung"},
+		},
+		{
+			name:        "token kembar hanya sekali",
+			description: "Kopi kopi KOPI",
+			want:        []string{"kopi"},
+		},
+		{
+			name:        "campuran huruf dan angka dipertahankan",
+			description: "Pulsa XL 50rb #INV-2026",
+			want:        []string{"pulsa", "xl", "50rb", "inv"},
+		},
+		{
+			name:        "huruf multi-byte",
+			description: "Kopi ☕ Café CAFÉ 日本語 é",
+			want:        []string{"kopi", "café", "日本語"},
+		},
+		{
+			name:        "token panjang dipotong per rune",
+			description: strings.Repeat("é", 150),
+			want:        []string{strings.Repeat("é", maxTokenRunes)},
+		},
+		{
+			name:        "token yang sama setelah dipotong hanya sekali",
+			description: strings.Repeat("a", maxTokenRunes) + "b " + strings.Repeat("a", maxTokenRunes) + "c",
+			want:        []string{strings.Repeat("a", maxTokenRunes)},
+		},
+		{
+			name:        "kosong",
+			description: " -- 12/05 ",
+			want:        []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.description)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Tokenize(%q) = %q, want %q", tt.description, got, tt.want)
+			}
+			for _, token := range got {
+				if !utf8.ValidString(token) || utf8.RuneCountInString(token) > maxTokenRunes {
+					t.Errorf("token %q is not valid UTF-8 of at most %d runes", token, maxTokenRunes)
+				}
+			}
+		})
+	}
+}
+
+func TestAmountToken(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{amount: 0, want: "amount:0"},
+		{amount: 0.5, want: "amount:0"},
+		{amount: 999, want: "amount:2"},
+		{amount: 1000, want: "amount:3"},
+		{amount: 50000, want: "amount:4"},
+	}
+
+	for _, tt := range tests {
+		if got := AmountToken(tt.amount); got != tt.want {
+			t.Errorf("AmountToken(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestBayesModelRank(t *testing.T) {
+	model := BayesModel{
+		DocCounts: map[uint]int{1: 3, 2: 1, 3: 0},
+		TokenCounts: map[uint]map[string]int{
+			1: {"kopi": 3, "susu": 1},
+			2: {"bensin": 1},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		model  BayesModel
+		tokens []string
+		want   []uint
+	}{
+		{name: "token kuat", model: model, tokens: []string{"kopi"}, want: []uint{1, 2}},
+		{name: "token kategori kecil", model: model, tokens: []string{"bensin"}, want: []uint{2, 1}},
+		{name: "model kosong", model: BayesModel{}, tokens: []string{"kopi"}, want: []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := tt.model.Rank(tt.tokens)
+			got := make([]uint, 0, len(suggestions))
+			var sum float64
+			for _, suggestion := range suggestions {
+				got = append(got, suggestion.CategoryID)
+				sum += suggestion.Confidence
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Rank() order = %v, want %v", got, tt.want)
+			}
+			if len(got) > 0 && math.Abs(sum-1) > 1e-9 {
+				t.Errorf("Rank() confidences sum to %v, want 1", sum)
+			}
+		})
+	}
+}
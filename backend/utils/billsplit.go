@@ -0,0 +1,95 @@
+package utils
+
+import "sort"
+
+// SplitByWeight membagi total (dalam sen) sesuai bobot masing-masing peserta.
+// Sisa pembulatan dibagikan satu sen per peserta, mulai dari pecahan terbesar,
+// sehingga jumlah hasilnya selalu sama dengan total.
+func SplitByWeight(total int64, weights []float64) []int64 {
+	shares := make([]int64, len(weights))
+	var sum float64
+	for _, weight := range weights {
+		sum += weight
+	}
+	if sum <= 0 {
+		return shares
+	}
+
+	remainders := make([]float64, len(weights))
+	allocated := int64(0)
+	for i, weight := range weights {
+		exact := float64(total) * weight / sum
+		shares[i] = int64(exact)
+		remainders[i] = exact - float64(shares[i])
+		allocated += shares[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; allocated < total; i = (i + 1) % len(order) {
+		shares[order[i]]++
+		allocated++
+	}
+	return shares
+}
+
+// SettlePayment adalah satu pembayaran pelunasan (dalam sen)
+type SettlePayment struct {
+	From   string
+	To     string
+	Amount int64
+}
+
+// SettleUp menyusun pembayaran pelunasan dari saldo bersih tiap orang (dalam
+// sen; positif berarti harus menerima, negatif berarti harus membayar).
+// Pengutang terbesar selalu membayar ke pemberi utang terbesar, sehingga
+// jumlah pembayaran paling banyak satu kurang dari jumlah orang yang saldonya
+// tidak nol.
+func SettleUp(balances map[string]int64) []SettlePayment {
+	type party struct {
+		name   string
+		amount int64
+	}
+	var creditors, debtors []party
+	for name, balance := range balances {
+		switch {
+		case balance > 0:
+			creditors = append(creditors, party{name, balance})
+		case balance < 0:
+			debtors = append(debtors, party{name, -balance})
+		}
+	}
+	byAmount := func(parties []party) func(i, j int) bool {
+		return func(i, j int) bool {
+			if parties[i].amount != parties[j].amount {
+				return parties[i].amount > parties[j].amount
+			}
+			return parties[i].name < parties[j].name
+		}
+	}
+
+	payments := []SettlePayment{}
+	for len(creditors) > 0 && len(debtors) > 0 {
+		sort.Slice(creditors, byAmount(creditors))
+		sort.Slice(debtors, byAmount(debtors))
+
+		amount := creditors[0].amount
+		if debtors[0].amount < amount {
+			amount = debtors[0].amount
+		}
+		payments = append(payments, SettlePayment{From: debtors[0].name, To: creditors[0].name, Amount: amount})
+
+		creditors[0].amount -= amount
+		debtors[0].amount -= amount
+		if creditors[0].amount == 0 {
+			creditors = creditors[1:]
+		}
+		if debtors[0].amount == 0 {
+			debtors = debtors[1:]
+		}
+	}
+	return payments
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitByWeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []float64
+		want    []int64
+	}{
+		{name: "rata tiga orang", total: 1000, weights: []float64{1, 1, 1}, want: []int64{334, 333, 333}},
+		{name: "bobot tidak rata", total: 100, weights: []float64{2, 1, 1}, want: []int64{50, 25, 25}},
+		{name: "sisa ke pecahan terbesar", total: 100, weights: []float64{1, 2}, want: []int64{33, 67}},
+		{name: "bobot pecahan", total: 10001, weights: []float64{0.5, 0.25, 0.25}, want: []int64{5001, 2500, 2500}},
+		{name: "satu sen untuk dua orang", total: 1, weights: []float64{1, 1}, want: []int64{1, 0}},
+		{name: "peserta berbobot nol", total: 999, weights: []float64{0, 1, 2}, want: []int64{0, 333, 666}},
+		{name: "total nol", total: 0, weights: []float64{1, 1}, want: []int64{0, 0}},
+		{name: "semua bobot nol", total: 1000, weights: []float64{0, 0}, want: []int64{0, 0}},
+		{name: "tanpa peserta", total: 1000, weights: nil, want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitByWeight(tt.total, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SplitByWeight(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+
+			var weightSum float64
+			for _, weight := range tt.weights {
+				weightSum += weight
+			}
+			var sum int64
+			for _, share := range got {
+				sum += share
+			}
+			if weightSum > 0 && sum != tt.total {
+				t.Errorf("shares sum to %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestSettleUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[string]int64
+		want     []SettlePayment
+	}{
+		{name: "tanpa saldo", balances: nil, want: []SettlePayment{}},
+		{name: "sudah lunas", balances: map[string]int64{"Ani": 0, "Budi": 0}, want: []SettlePayment{}},
+		{
+			name:     "satu pemberi utang",
+			balances: map[string]int64{"Ani": 3000, "Budi": -1000, "Sari": -2000},
+			want: []SettlePayment{
+				{From: "Sari", To: "Ani", Amount: 2000},
+				{From: "Budi", To: "Ani", Amount: 1000},
+			},
+		},
+		{
+			name:     "peserta yang sudah lunas dilewati",
+			balances: map[string]int64{"Ani": 500, "Budi": -500, "Sari": 0},
+			want:     []SettlePayment{{From: "Budi", To: "Ani", Amount: 500}},
+		},
+		{
+			name:     "banyak pemberi dan pengutang",
+			balances: map[string]int64{"Ani": 700, "Budi": 300, "Citra": -600, "Dodi": -400},
+			want: []SettlePayment{
+				{From: "Citra", To: "Ani", Amount: 600},
+				{From: "Dodi", To: "Budi", Amount: 300},
+				{From: "Dodi", To: "Ani", Amount: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SettleUp(tt.balances); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SettleUp(%v) = %+v, want %+v", tt.balances, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+// DefaultLanguage dipakai bila bahasa user tidak dikenal
+const DefaultLanguage = "id"
+
+// DefaultCategory adalah satu kategori bawaan. Key dipakai untuk mengenali
+// kategori yang sudah ada walaupun namanya berbeda bahasa atau sudah diubah.
+type DefaultCategory struct {
+	Key   string            `json:"key"`
+	Type  string            `json:"type"`
+	Names map[string]string `json:"names"`
+}
+
+// Name mengembalikan nama kategori dalam bahasa lang, dengan fallback ke
+// DefaultLanguage lalu ke key-nya
+func (d DefaultCategory) Name(lang string) string {
+	if name, ok := d.Names[lang]; ok && name != "" {
+		return name
+	}
+	if name, ok := d.Names[DefaultLanguage]; ok && name != "" {
+		return name
+	}
+	return d.Key
+}
+
+var builtinDefaultCategories = []DefaultCategory{
+	{Key: "salary", Type: "income", Names: map[string]string{"id": "Gaji", "en": "Salary"}},
+	{Key: "bonus", Type: "income", Names: map[string]string{"id": "Bonus", "en": "Bonus"}},
+	{Key: "investment_income", Type: "income", Names: map[string]string{"id": "Hasil Investasi", "en": "Investment Income"}},
+	{Key: "other_income", Type: "income", Names: map[string]string{"id": "Pemasukan Lain", "en": "Other Income"}},
+	{Key: "food", Type: "expense", Names: map[string]string{"id": "Makanan", "en": "Food"}},
+	{Key: "transportation", Type: "expense", Names: map[string]string{"id": "Transportasi", "en": "Transportation"}},
+	{Key: "bills", Type: "expense", Names: map[string]string{"id": "Tagihan", "en": "Bills"}},
+	{Key: "shopping", Type: "expense", Names: map[string]string{"id": "Belanja", "en": "Shopping"}},
+	{Key: "health", Type: "expense", Names: map[string]string{"id": "Kesehatan", "en": "Health"}},
+	{Key: "education", Type: "expense", Names: map[string]string{"id": "Pendidikan", "en": "Education"}},
+	{Key: "entertainment", Type: "expense", Names: map[string]string{"id": "Hiburan", "en": "Entertainment"}},
+	{Key: "other_expense", Type: "expense", Names: map[string]string{"id": "Pengeluaran Lain", "en": "Other Expenses"}},
+}
+
+var (
+	defaultCategoriesOnce sync.Once
+	defaultCategories     []DefaultCategory
+)
+
+// DefaultCategories mengembalikan daftar kategori bawaan. Daftar bisa diganti
+// dengan file JSON pada env DEFAULT_CATEGORIES_PATH; bila file tidak valid,
+// daftar bawaan yang dipakai.
+func DefaultCategories() []DefaultCategory {
+	defaultCategoriesOnce.Do(func() {
+		defaultCategories = builtinDefaultCategories
+		path := os.Getenv("DEFAULT_CATEGORIES_PATH")
+		if path == "" {
+			return
+		}
+		loaded, err := loadDefaultCategories(path)
+		if err != nil {
+			log.Println("Failed to load default categories, using built-in set:", err)
+			return
+		}
+		defaultCategories = loaded
+	})
+	return defaultCategories
+}
+
+// SupportedLanguages mengembalikan bahasa yang dikenal dari nama-nama kategori
+// bawaan yang sedang dipakai (termasuk dari DEFAULT_CATEGORIES_PATH), urut abjad
+func SupportedLanguages() []string {
+	seen := map[string]bool{DefaultLanguage: true}
+	for _, category := range DefaultCategories() {
+		for lang := range category.Names {
+			seen[lang] = true
+		}
+	}
+	languages := make([]string, 0, len(seen))
+	for lang := range seen {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// IsSupportedLanguage bernilai true bila lang ada di SupportedLanguages
+func IsSupportedLanguage(lang string) bool {
+	for _, supported := range SupportedLanguages() {
+		if supported == lang {
+			return true
+		}
+	}
+	return false
+}
+
+func loadDefaultCategories(path string) ([]DefaultCategory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var categories []DefaultCategory
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, category := range categories {
+		if category.Key == "" || seen[category.Key] {
+			return nil, fmt.Errorf("category key %q is empty or duplicated", category.Key)
+		}
+		if category.Type != "income" && category.Type != "expense" {
+			return nil, fmt.Errorf("category %q has invalid type %q", category.Key, category.Type)
+		}
+		seen[category.Key] = true
+	}
+	return categories, nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"math"
+	"time"
+)
+
+// daysPerMonth adalah rata-rata jumlah hari dalam sebulan, dipakai untuk
+// mengubah selisih tanggal menjadi jumlah bulan
+const daysPerMonth = 365.25 / 12
+
+// GoalProjection adalah perkiraan pencapaian target tabungan
+type GoalProjection struct {
+	Percentage float64 `json:"percentage"`
+	Remaining  float64 `json:"remaining"`
+	Achieved   bool    `json:"achieved"`
+	// Setoran per bulan agar target tercapai tepat pada tanggal target;
+	// kosong bila target tidak punya tanggal atau sudah tercapai
+	RequiredMonthly *float64 `json:"required_monthly,omitempty"`
+	// Rata-rata setoran per bulan selama beberapa bulan terakhir
+	MonthlyPace float64 `json:"monthly_pace"`
+	// Perkiraan tanggal tercapai dengan laju MonthlyPace; kosong bila sudah
+	// tercapai atau tidak ada setoran baru-baru ini
+	ProjectedDate *time.Time `json:"projected_completion_date,omitempty"`
+	// Bernilai true bila ProjectedDate tidak melewati tanggal target
+	OnTrack *bool `json:"on_track,omitempty"`
+}
+
+// monthsBetween menghitung jumlah bulan (pecahan) dari from sampai to
+func monthsBetween(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / daysPerMonth
+}
+
+// ProjectGoal menghitung progres target tabungan. recent adalah total setoran
+// selama paceMonths bulan terakhir sebelum today.
+func ProjectGoal(target, saved, recent float64, paceMonths int, targetDate *time.Time, today time.Time) GoalProjection {
+	today = DateOf(today)
+	projection := GoalProjection{
+		Remaining:   math.Max(math.Round((target-saved)*100)/100, 0),
+		MonthlyPace: math.Round(recent/float64(paceMonths)*100) / 100,
+	}
+	if target > 0 {
+		projection.Percentage = math.Round(saved/target*10000) / 100
+	}
+	projection.Achieved = projection.Remaining == 0
+	if projection.Achieved {
+		return projection
+	}
+
+	if targetDate != nil {
+		// Target yang sudah lewat atau tinggal kurang dari sebulan harus
+		// dilunasi dalam satu kali setoran
+		months := math.Max(monthsBetween(today, DateOf(*targetDate)), 1)
+		required := math.Ceil(projection.Remaining/months*100) / 100
+		projection.RequiredMonthly = &required
+	}
+
+	if projection.MonthlyPace > 0 {
+		days := int(math.Ceil(projection.Remaining / projection.MonthlyPace * daysPerMonth))
+		projected := today.AddDate(0, 0, days)
+		projection.ProjectedDate = &projected
+		if targetDate != nil {
+			onTrack := !projected.After(DateOf(*targetDate))
+			projection.OnTrack = &onTrack
+		}
+	} else if targetDate != nil {
+		onTrack := false
+		projection.OnTrack = &onTrack
+	}
+	return projection
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func timePtr(v time.Time) *time.Time { return &v }
+
+func TestProjectGoal(t *testing.T) {
+	today := time.Date(2026, time.January, 1, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		target     float64
+		saved      float64
+		recent     float64
+		paceMonths int
+		targetDate *time.Time
+		want       GoalProjection
+	}{
+		{
+			name:       "sudah tercapai",
+			target:     1000,
+			saved:      1200,
+			recent:     300,
+			paceMonths: 3,
+			targetDate: timePtr(date(2027, time.January, 1)),
+			want:       GoalProjection{Percentage: 120, Remaining: 0, Achieved: true, MonthlyPace: 100},
+		},
+		{
+			name:       "tanpa tanggal target dan tanpa setoran",
+			target:     1000,
+			saved:      250,
+			paceMonths: 3,
+			want:       GoalProjection{Percentage: 25, Remaining: 750},
+		},
+		{
+			name:       "tanpa setoran tidak pernah on track",
+			target:     1200,
+			paceMonths: 3,
+			targetDate: timePtr(date(2027, time.January, 1)),
+			want: GoalProjection{
+				Remaining:       1200,
+				RequiredMonthly: floatPtr(100.07),
+				OnTrack:         boolPtr(false),
+			},
+		},
+		{
+			name:       "proyeksi lewat sehari dari target",
+			target:     1200,
+			recent:     300,
+			paceMonths: 3,
+			targetDate: timePtr(date(2027, time.January, 1)),
+			want: GoalProjection{
+				Remaining:       1200,
+				RequiredMonthly: floatPtr(100.07),
+				MonthlyPace:     100,
+				ProjectedDate:   timePtr(date(2027, time.January, 2)),
+				OnTrack:         boolPtr(false),
+			},
+		},
+		{
+			name:       "proyeksi tepat pada tanggal target",
+			target:     1200,
+			recent:     300,
+			paceMonths: 3,
+			targetDate: timePtr(date(2027, time.January, 2)),
+			want: GoalProjection{
+				Remaining:       1200,
+				RequiredMonthly: floatPtr(99.8),
+				MonthlyPace:     100,
+				ProjectedDate:   timePtr(date(2027, time.January, 2)),
+				OnTrack:         boolPtr(true),
+			},
+		},
+		{
+			name:       "tanggal target sudah lewat",
+			target:     1000,
+			saved:      500,
+			paceMonths: 3,
+			targetDate: timePtr(date(2025, time.December, 1)),
+			want: GoalProjection{
+				Percentage:      50,
+				Remaining:       500,
+				RequiredMonthly: floatPtr(500),
+				OnTrack:         boolPtr(false),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProjectGoal(tt.target, tt.saved, tt.recent, tt.paceMonths, tt.targetDate, today)
+			if got.Percentage != tt.want.Percentage || got.Remaining != tt.want.Remaining ||
+				got.Achieved != tt.want.Achieved || got.MonthlyPace != tt.want.MonthlyPace {
+				t.Errorf("ProjectGoal() = %+v, want %+v", got, tt.want)
+			}
+			if !equalPtr(got.RequiredMonthly, tt.want.RequiredMonthly, func(a, b float64) bool { return a == b }) {
+				t.Errorf("RequiredMonthly = %v, want %v", deref(got.RequiredMonthly), deref(tt.want.RequiredMonthly))
+			}
+			if !equalPtr(got.ProjectedDate, tt.want.ProjectedDate, time.Time.Equal) {
+				t.Errorf("ProjectedDate = %v, want %v", deref(got.ProjectedDate), deref(tt.want.ProjectedDate))
+			}
+			if !equalPtr(got.OnTrack, tt.want.OnTrack, func(a, b bool) bool { return a == b }) {
+				t.Errorf("OnTrack = %v, want %v", deref(got.OnTrack), deref(tt.want.OnTrack))
+			}
+		})
+	}
+}
+
+func equalPtr[T any](a, b *T, equal func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return equal(*a, *b)
+}
+
+func deref[T any](v *T) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
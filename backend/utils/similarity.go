@@ -0,0 +1,28 @@
+package utils
+
+import "strings"
+
+// DescriptionSimilarity mengukur kemiripan dua deskripsi transaksi (0..1)
+// dengan koefisien Dice atas token dari Tokenize. Deskripsi tanpa token
+// (kosong atau hanya angka) hanya dianggap mirip bila teksnya sama persis.
+func DescriptionSimilarity(a, b string) float64 {
+	tokensA, tokensB := Tokenize(a), Tokenize(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) {
+			return 1
+		}
+		return 0
+	}
+
+	set := make(map[string]bool, len(tokensA))
+	for _, token := range tokensA {
+		set[token] = true
+	}
+	shared := 0
+	for _, token := range tokensB {
+		if set[token] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(tokensA)+len(tokensB))
+}
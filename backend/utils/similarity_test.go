@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDescriptionSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "sama tanpa membedakan huruf besar", a: "Grab ride", b: "grab RIDE", want: 1},
+		{name: "urutan dan angka diabaikan", a: "Indomaret 0123 belanja", b: "belanja indomaret", want: 1},
+		{name: "sebagian token sama", a: "Grab ride office", b: "Grab ride home", want: 2.0 / 3},
+		{name: "tidak ada token sama", a: "Kopi susu", b: "Bensin motor", want: 0},
+		{name: "huruf multi-byte", a: "Café Kopi", b: "CAFÉ teh", want: 0.5},
+		{name: "hanya angka dan sama", a: " 12345 ", b: "12345", want: 1},
+		{name: "hanya angka dan berbeda", a: "12345", b: "67890", want: 0},
+		{name: "satu sisi kosong", a: "", b: "Kopi", want: 0},
+		{name: "keduanya kosong", a: "", b: "  ", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescriptionSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("DescriptionSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := DescriptionSimilarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("DescriptionSimilarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Aturan pembayaran minimum kartu kredit: 5% dari tagihan, paling sedikit
+// batas bawah kartu, dan tidak lebih dari total tagihan
+const MinimumPaymentRate = 0.05
+
+// minimumPaymentFloors adalah batas bawah pembayaran minimum bawaan per mata
+// uang. Mata uang lain tidak punya batas bawah kecuali diatur per dompet.
+var minimumPaymentFloors = map[string]float64{
+	"IDR": 50000,
+}
+
+// DefaultMinimumPaymentFloor mengembalikan batas bawah pembayaran minimum
+// bawaan untuk mata uang
+func DefaultMinimumPaymentFloor(currency string) float64 {
+	return minimumPaymentFloors[strings.ToUpper(currency)]
+}
+
+// StatementPeriod adalah satu siklus tagihan kartu kredit. Start dan Closing
+// sama-sama inklusif; tagihan dicetak pada Closing dan harus dibayar paling
+// lambat Due.
+type StatementPeriod struct {
+	Start   time.Time `json:"period_start"`
+	Closing time.Time `json:"closing_date"`
+	Due     time.Time `json:"due_date"`
+}
+
+// DateOf mengambil tanggal kalender dari t sebagai tengah malam UTC, sama
+// seperti kolom bertipe date yang dibaca dari database
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayInMonth membuat tanggal pada bulan tertentu, dibatasi ke hari terakhir
+// bulan itu (mis. tanggal 31 pada bulan Februari menjadi 28/29)
+func dayInMonth(year int, month time.Month, day int) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// closingOnOrBefore mencari tanggal cetak tagihan terakhir yang <= date
+func closingOnOrBefore(date time.Time, statementDay int) time.Time {
+	closing := dayInMonth(date.Year(), date.Month(), statementDay)
+	if closing.After(date) {
+		closing = dayInMonth(date.Year(), date.Month()-1, statementDay)
+	}
+	return closing
+}
+
+// periodEndingAt menyusun siklus yang dicetak pada tanggal closing
+func periodEndingAt(closing time.Time, statementDay, dueDay int) StatementPeriod {
+	previous := dayInMonth(closing.Year(), closing.Month()-1, statementDay)
+
+	due := dayInMonth(closing.Year(), closing.Month(), dueDay)
+	if !due.After(closing) {
+		due = dayInMonth(closing.Year(), closing.Month()+1, dueDay)
+	}
+
+	return StatementPeriod{
+		Start:   previous.AddDate(0, 0, 1),
+		Closing: closing,
+		Due:     due,
+	}
+}
+
+// StatementPeriods mengembalikan count siklus yang sudah dicetak per tanggal
+// today, diurutkan dari yang terbaru
+func StatementPeriods(statementDay, dueDay int, today time.Time, count int) []StatementPeriod {
+	today = DateOf(today)
+	closing := closingOnOrBefore(today, statementDay)
+
+	periods := make([]StatementPeriod, 0, count)
+	for i := 0; i < count; i++ {
+		period := periodEndingAt(closing, statementDay, dueDay)
+		periods = append(periods, period)
+		closing = period.Start.AddDate(0, 0, -1)
+	}
+	return periods
+}
+
+// CurrentPeriod mengembalikan siklus berjalan yang belum dicetak
+func CurrentPeriod(statementDay, dueDay int, today time.Time) StatementPeriod {
+	today = DateOf(today)
+	last := closingOnOrBefore(today, statementDay)
+	next := dayInMonth(last.Year(), last.Month()+1, statementDay)
+	return periodEndingAt(next, statementDay, dueDay)
+}
+
+// MinimumPayment menghitung pembayaran minimum dari total tagihan dengan
+// batas bawah floor
+func MinimumPayment(statementBalance, floor float64) float64 {
+	if statementBalance <= 0 {
+		return 0
+	}
+	minimum := math.Max(math.Round(statementBalance*MinimumPaymentRate*100)/100, floor)
+	return math.Min(minimum, statementBalance)
+}
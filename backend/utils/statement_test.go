@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStatementPeriods(t *testing.T) {
+	tests := []struct {
+		name         string
+		statementDay int
+		dueDay       int
+		today        time.Time
+		want         []StatementPeriod
+	}{
+		{
+			name:         "tanggal cetak 31 pada bulan Februari",
+			statementDay: 31,
+			dueDay:       15,
+			today:        date(2026, time.March, 10),
+			want: []StatementPeriod{
+				{Start: date(2026, time.February, 1), Closing: date(2026, time.February, 28), Due: date(2026, time.March, 15)},
+				{Start: date(2026, time.January, 1), Closing: date(2026, time.January, 31), Due: date(2026, time.February, 15)},
+			},
+		},
+		{
+			name:         "tanggal cetak 31 pada Februari tahun kabisat",
+			statementDay: 31,
+			dueDay:       15,
+			today:        date(2028, time.March, 1),
+			want: []StatementPeriod{
+				{Start: date(2028, time.February, 1), Closing: date(2028, time.February, 29), Due: date(2028, time.March, 15)},
+			},
+		},
+		{
+			name:         "hari ini tepat tanggal cetak",
+			statementDay: 25,
+			dueDay:       10,
+			today:        date(2026, time.October, 25),
+			want: []StatementPeriod{
+				{Start: date(2026, time.September, 26), Closing: date(2026, time.October, 25), Due: date(2026, time.November, 10)},
+			},
+		},
+		{
+			name:         "siklus melewati pergantian tahun",
+			statementDay: 5,
+			dueDay:       20,
+			today:        date(2026, time.January, 3),
+			want: []StatementPeriod{
+				{Start: date(2025, time.November, 6), Closing: date(2025, time.December, 5), Due: date(2025, time.December, 20)},
+			},
+		},
+		{
+			name:         "jam pada today diabaikan",
+			statementDay: 25,
+			dueDay:       10,
+			today:        time.Date(2026, time.October, 25, 23, 59, 0, 0, time.UTC),
+			want: []StatementPeriod{
+				{Start: date(2026, time.September, 26), Closing: date(2026, time.October, 25), Due: date(2026, time.November, 10)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StatementPeriods(tt.statementDay, tt.dueDay, tt.today, len(tt.want))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d periods, want %d", len(got), len(tt.want))
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("period %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCurrentPeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		statementDay int
+		dueDay       int
+		today        time.Time
+		want         StatementPeriod
+	}{
+		{
+			name:         "tanggal cetak 31 ditutup pada akhir Februari",
+			statementDay: 31,
+			dueDay:       15,
+			today:        date(2026, time.February, 10),
+			want:         StatementPeriod{Start: date(2026, time.February, 1), Closing: date(2026, time.February, 28), Due: date(2026, time.March, 15)},
+		},
+		{
+			name:         "jatuh tempo bulan berikutnya",
+			statementDay: 25,
+			dueDay:       10,
+			today:        date(2026, time.October, 19),
+			want:         StatementPeriod{Start: date(2026, time.September, 26), Closing: date(2026, time.October, 25), Due: date(2026, time.November, 10)},
+		},
+		{
+			name:         "sehari setelah tanggal cetak",
+			statementDay: 25,
+			dueDay:       10,
+			today:        date(2026, time.October, 26),
+			want:         StatementPeriod{Start: date(2026, time.October, 26), Closing: date(2026, time.November, 25), Due: date(2026, time.December, 10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CurrentPeriod(tt.statementDay, tt.dueDay, tt.today); got != tt.want {
+				t.Errorf("CurrentPeriod() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinimumPayment(t *testing.T) {
+	tests := []struct {
+		balance float64
+		floor   float64
+		want    float64
+	}{
+		{balance: 0, floor: 50000, want: 0},
+		{balance: -100000, floor: 50000, want: 0},
+		{balance: 30000, floor: 50000, want: 30000},
+		{balance: 100000, floor: 50000, want: 50000},
+		{balance: 2000000, floor: 50000, want: 100000},
+		{balance: 1234567, floor: 50000, want: 61728.35},
+		{balance: 100, floor: 0, want: 5},
+		{balance: 100, floor: 25, want: 25},
+	}
+
+	for _, tt := range tests {
+		if got := MinimumPayment(tt.balance, tt.floor); got != tt.want {
+			t.Errorf("MinimumPayment(%v, %v) = %v, want %v", tt.balance, tt.floor, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultMinimumPaymentFloor(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     float64
+	}{
+		{currency: "IDR", want: 50000},
+		{currency: "idr", want: 50000},
+		{currency: "USD", want: 0},
+		{currency: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultMinimumPaymentFloor(tt.currency); got != tt.want {
+			t.Errorf("DefaultMinimumPaymentFloor(%q) = %v, want %v", tt.currency, got, tt.want)
+		}
+	}
+}